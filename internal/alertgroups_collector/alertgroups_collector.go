@@ -0,0 +1,163 @@
+package alertgroupscollector
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/prometheus/alertmanager/api/v2/client/alertgroup"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"golang.org/x/exp/slices"
+)
+
+// states are the alert states exported for every group.
+var states = []string{models.AlertStatusStateActive, models.AlertStatusStateSuppressed, models.AlertStatusStateUnprocessed}
+
+// AlertGroupsCollector exports the alert groups of an Alertmanager.
+// Alertmanager groups alerts per receiver by the labels configured in the route's 'group_by'.
+type AlertGroupsCollector struct {
+	AlertGroupService alertgroup.ClientService
+
+	// Filters is a list of Alertmanager matchers to filter alerts by.
+	Filters []string
+	// Receiver is a regex matching the receivers to query. All receivers are queried if empty.
+	Receiver string
+
+	// ConstLabels are added to every exported series.
+	ConstLabels prometheus.Labels
+}
+
+var _ prometheus.Collector = &AlertGroupsCollector{}
+
+// Describe implements prometheus.Collector.
+// Does not send any description and thus makes the collector unchecked.
+func (o *AlertGroupsCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+// It queries the Alertmanager API and sends the number of alerts per group and state and the number of groups per receiver.
+// The scrape does not fail if the query fails, the outcome is exported in 'alerts_exporter_alert_groups_up'.
+func (o *AlertGroupsCollector) Collect(ch chan<- prometheus.Metric) {
+	p := alertgroup.NewGetAlertGroupsParamsWithContext(context.Background()).
+		WithFilter(o.Filters)
+	if o.Receiver != "" {
+		p = p.WithReceiver(&o.Receiver)
+	}
+
+	gs, err := o.AlertGroupService.GetAlertGroups(p)
+	if err != nil {
+		log.Print("Error querying Alertmanager alert groups", err)
+	}
+	up := 0.0
+	if err == nil {
+		up = 1
+	}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_alert_groups_up", "Whether the last query to the Alertmanager alert groups API succeeded.", nil, o.ConstLabels),
+		prometheus.GaugeValue,
+		up,
+	)
+	if err != nil {
+		return
+	}
+
+	groupsDesc := prometheus.NewDesc("alerts_exporter_alert_groups", "Number of alert groups per receiver queried from the Alertmanager API.", []string{"receiver"}, o.ConstLabels)
+	groups := make(map[string]int)
+	var receivers []string
+	// Routes with the same receiver and group labels, for example with 'continue: true', return separate groups.
+	// Their alerts are summed to export one series per receiver and group labels.
+	counts := make(map[string]*groupCounts)
+	var order []string
+	for _, g := range gs.Payload {
+		receiver := ""
+		if g.Receiver != nil {
+			receiver = deref(g.Receiver.Name)
+		}
+		if _, ok := groups[receiver]; !ok {
+			receivers = append(receivers, receiver)
+		}
+		groups[receiver]++
+
+		keys, values := o.groupLabels(receiver, g)
+		id := strings.Join(keys, "\xff") + "\xfe" + strings.Join(values, "\xff")
+		c, ok := counts[id]
+		if !ok {
+			c = &groupCounts{keys: keys, values: values, states: make(map[string]int, len(states))}
+			counts[id] = c
+			order = append(order, id)
+		}
+		for _, a := range g.Alerts {
+			if a.Status != nil {
+				c.states[deref(a.Status.State)]++
+			}
+		}
+	}
+	for _, id := range order {
+		if err := o.collectGroup(ch, counts[id]); err != nil {
+			log.Print("Error exporting alert group", err)
+		}
+	}
+	for _, r := range receivers {
+		ch <- prometheus.MustNewConstMetric(groupsDesc, prometheus.GaugeValue, float64(groups[r]), r)
+	}
+}
+
+// groupCounts are the number of alerts per state of the groups with the given labels.
+type groupCounts struct {
+	keys, values []string
+	states       map[string]int
+}
+
+// groupLabels returns the sorted label names and values of the given group, without the state label.
+// The labels are the receiver and the group labels.
+// Group labels colliding with the receiver, the state or the constant labels are renamed to 'exported_<name>', invalid group labels are skipped.
+func (o *AlertGroupsCollector) groupLabels(receiver string, g *models.AlertGroup) (keys, values []string) {
+	labels := make(map[string]string, len(g.Labels)+1)
+	for k, v := range g.Labels {
+		if !model.LabelName(k).IsValidLegacy() || strings.HasPrefix(k, model.ReservedLabelPrefix) {
+			continue
+		}
+		if _, ok := o.ConstLabels[k]; ok || k == "receiver" || k == "state" {
+			k = "exported_" + k
+		}
+		labels[k] = v
+	}
+	labels["receiver"] = receiver
+
+	keys = make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	values = make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = labels[k]
+	}
+	return keys, values
+}
+
+// collectGroup sends the number of alerts per state of the given groups.
+func (o *AlertGroupsCollector) collectGroup(ch chan<- prometheus.Metric, c *groupCounts) error {
+	keys := append(slices.Clone(c.keys), "state")
+	values := append(slices.Clone(c.values), "")
+
+	desc := prometheus.NewDesc("alerts_exporter_alert_group_alerts", "Number of alerts per state in alert groups queried from the Alertmanager API. Group labels are added as labels.", keys, o.ConstLabels)
+	for _, s := range states {
+		values[len(values)-1] = s
+		m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, float64(c.states[s]), values...)
+		if err != nil {
+			return err
+		}
+		ch <- m
+	}
+	return nil
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
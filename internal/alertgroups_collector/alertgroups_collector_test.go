@@ -0,0 +1,124 @@
+package alertgroupscollector_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alertgroup"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertgroupscollector "github.com/appuio/alerts_exporter/internal/alertgroups_collector"
+	"github.com/appuio/alerts_exporter/internal/alertgroups_collector/mock"
+)
+
+//go:generate go run github.com/golang/mock/mockgen -destination=./mock/alertgroup_service.go -package mock github.com/prometheus/alertmanager/api/v2/client/alertgroup ClientService
+
+func TestAlertGroupsCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	alertWithState := func(state string) *models.GettableAlert {
+		return &models.GettableAlert{Status: &models.AlertStatus{State: ptr(state)}}
+	}
+
+	mockAlertGroupService := mock.NewMockClientService(ctrl)
+	mockAlertGroupService.
+		EXPECT().
+		GetAlertGroups(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(p *alertgroup.GetAlertGroupsParams, _ ...alertgroup.ClientOption) (*alertgroup.GetAlertGroupsOK, error) {
+			require.Equal(t, "team-.*", *p.Receiver)
+			return &alertgroup.GetAlertGroupsOK{
+				Payload: models.AlertGroups{
+					{
+						Receiver: &models.Receiver{Name: ptr("team-a")},
+						Labels:   models.LabelSet{"alertname": "ImportantAlert", "namespace": "a"},
+						Alerts: []*models.GettableAlert{
+							alertWithState(models.AlertStatusStateActive),
+							alertWithState(models.AlertStatusStateActive),
+							alertWithState(models.AlertStatusStateSuppressed),
+						},
+					},
+					{
+						Receiver: &models.Receiver{Name: ptr("team-a")},
+						Labels:   models.LabelSet{"alertname": "OtherAlert", "namespace": "a"},
+						Alerts: []*models.GettableAlert{
+							alertWithState(models.AlertStatusStateActive),
+						},
+					},
+					{
+						// Same receiver and group labels from another route.
+						Receiver: &models.Receiver{Name: ptr("team-a")},
+						Labels:   models.LabelSet{"alertname": "ImportantAlert", "namespace": "a"},
+						Alerts: []*models.GettableAlert{
+							alertWithState(models.AlertStatusStateActive),
+							alertWithState(models.AlertStatusStateUnprocessed),
+						},
+					},
+					{
+						Receiver: &models.Receiver{Name: ptr("team-b")},
+						Labels:   models.LabelSet{"receiver": "team-b-override"},
+						Alerts: []*models.GettableAlert{
+							alertWithState(models.AlertStatusStateUnprocessed),
+						},
+					},
+				},
+			}, nil
+		})
+
+	subject := &alertgroupscollector.AlertGroupsCollector{
+		AlertGroupService: mockAlertGroupService,
+		Receiver:          "team-.*",
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alert_group_alerts Number of alerts per state in alert groups queried from the Alertmanager API. Group labels are added as labels.
+# TYPE alerts_exporter_alert_group_alerts gauge
+alerts_exporter_alert_group_alerts{alertname="ImportantAlert",namespace="a",receiver="team-a",state="active"} 3
+alerts_exporter_alert_group_alerts{alertname="ImportantAlert",namespace="a",receiver="team-a",state="suppressed"} 1
+alerts_exporter_alert_group_alerts{alertname="ImportantAlert",namespace="a",receiver="team-a",state="unprocessed"} 1
+alerts_exporter_alert_group_alerts{alertname="OtherAlert",namespace="a",receiver="team-a",state="active"} 1
+alerts_exporter_alert_group_alerts{alertname="OtherAlert",namespace="a",receiver="team-a",state="suppressed"} 0
+alerts_exporter_alert_group_alerts{alertname="OtherAlert",namespace="a",receiver="team-a",state="unprocessed"} 0
+alerts_exporter_alert_group_alerts{exported_receiver="team-b-override",receiver="team-b",state="active"} 0
+alerts_exporter_alert_group_alerts{exported_receiver="team-b-override",receiver="team-b",state="suppressed"} 0
+alerts_exporter_alert_group_alerts{exported_receiver="team-b-override",receiver="team-b",state="unprocessed"} 1
+# HELP alerts_exporter_alert_groups Number of alert groups per receiver queried from the Alertmanager API.
+# TYPE alerts_exporter_alert_groups gauge
+alerts_exporter_alert_groups{receiver="team-a"} 3
+alerts_exporter_alert_groups{receiver="team-b"} 1
+# HELP alerts_exporter_alert_groups_up Whether the last query to the Alertmanager alert groups API succeeded.
+# TYPE alerts_exporter_alert_groups_up gauge
+alerts_exporter_alert_groups_up 1
+`),
+		),
+	)
+}
+
+func TestAlertGroupsCollector_Err(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertGroupService := mock.NewMockClientService(ctrl)
+	mockAlertGroupService.
+		EXPECT().
+		GetAlertGroups(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("API error"))
+
+	subject := &alertgroupscollector.AlertGroupsCollector{
+		AlertGroupService: mockAlertGroupService,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alert_groups_up Whether the last query to the Alertmanager alert groups API succeeded.
+# TYPE alerts_exporter_alert_groups_up gauge
+alerts_exporter_alert_groups_up 0
+`),
+		),
+	)
+}
+
+func ptr[T any](t T) *T { return &t }
@@ -0,0 +1,80 @@
+package alertscollector
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slices"
+)
+
+// ValidateAggregateBy returns an error if any of the given labels is not a valid label name.
+func ValidateAggregateBy(labels []string) error {
+	for _, l := range labels {
+		if !isValidLabelName(l) {
+			return fmt.Errorf("invalid aggregation label %q", l)
+		}
+	}
+	return nil
+}
+
+// collectAggregated sends the number of the given alerts grouped by the collector's AggregateBy labels and the alert state.
+// Alerts missing a label are grouped under an empty value.
+func (o *AlertsCollector) collectAggregated(ch chan<- prometheus.Metric, as []*models.GettableAlert, cs *collectionStats) {
+	keys := slices.Clone(o.AggregateBy)
+	for i, k := range keys {
+		// Alert labels named like const labels are renamed by alertLabels, group by the renamed label.
+		if _, ok := o.ConstLabels[k]; ok {
+			keys[i] = "exported_" + k
+		}
+	}
+	stateKey := o.labelPrefix() + stateLabel
+	stateIndex := slices.Index(keys, stateKey)
+	if stateIndex < 0 {
+		keys = append(keys, stateKey)
+		stateIndex = len(keys) - 1
+	}
+	desc := prometheus.NewDesc(
+		o.metricName()+"_count",
+		"Number of alerts queried from the Alertmanager API grouped by labels and state.",
+		keys,
+		o.ConstLabels,
+	)
+
+	counts := make(map[string]int)
+	groups := make(map[string][]string)
+	var order []string
+	for _, a := range as {
+		labels, ok := o.alertLabels(a, cs)
+		if !ok {
+			continue
+		}
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			values[i] = labels[k]
+		}
+		values[stateIndex] = ""
+		if a.Status != nil {
+			values[stateIndex] = deref(a.Status.State)
+		}
+
+		g := strings.Join(values, "\xff")
+		if _, ok := groups[g]; !ok {
+			groups[g] = values
+			order = append(order, g)
+		}
+		counts[g]++
+	}
+
+	for _, g := range order {
+		m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, float64(counts[g]), groups[g]...)
+		if err != nil {
+			log.Print("Error exporting alert count", err)
+			cs.exportErrors++
+			continue
+		}
+		ch <- m
+	}
+}
@@ -0,0 +1,99 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+)
+
+func TestAlertsCollector_AggregateBy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	newAlert := func(namespace, severity, state string) *models.GettableAlert {
+		labels := map[string]string{"alertname": "PodCrashLooping", "severity": severity, "pod": "pod-" + namespace}
+		if namespace != "" {
+			labels["namespace"] = namespace
+		}
+		return &models.GettableAlert{
+			Alert:  models.Alert{Labels: labels},
+			Status: &models.AlertStatus{State: &state},
+		}
+	}
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				newAlert("a", "critical", "active"),
+				newAlert("a", "critical", "active"),
+				newAlert("a", "critical", "suppressed"),
+				newAlert("a", "warning", "active"),
+				newAlert("b", "critical", "active"),
+				newAlert("", "critical", "active"),
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		AggregateBy: []string{"namespace", "severity"},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts_count Number of alerts queried from the Alertmanager API grouped by labels and state.
+# TYPE alerts_exporter_alerts_count gauge
+alerts_exporter_alerts_count{_alerts_exporter_alert_state="active",namespace="",severity="critical"} 1
+alerts_exporter_alerts_count{_alerts_exporter_alert_state="active",namespace="a",severity="critical"} 2
+alerts_exporter_alerts_count{_alerts_exporter_alert_state="active",namespace="a",severity="warning"} 1
+alerts_exporter_alerts_count{_alerts_exporter_alert_state="active",namespace="b",severity="critical"} 1
+alerts_exporter_alerts_count{_alerts_exporter_alert_state="suppressed",namespace="a",severity="critical"} 1
+`),
+			"alerts_exporter_alerts", "alerts_exporter_alerts_count",
+		),
+	)
+}
+
+func TestAlertsCollector_AggregateBy_ConstLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{
+					Alert:  models.Alert{Labels: map[string]string{"alertname": "Watchdog", "tenant": "team-a"}},
+					Status: &models.AlertStatus{State: ptr("active")},
+				},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		AggregateBy: []string{"alertname", "tenant"},
+		ConstLabels: map[string]string{"tenant": "mimir-a"},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts_count Number of alerts queried from the Alertmanager API grouped by labels and state.
+# TYPE alerts_exporter_alerts_count gauge
+alerts_exporter_alerts_count{_alerts_exporter_alert_state="active",alertname="Watchdog",exported_tenant="team-a",tenant="mimir-a"} 1
+`),
+			"alerts_exporter_alerts_count",
+		),
+	)
+}
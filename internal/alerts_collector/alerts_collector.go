@@ -2,29 +2,105 @@ package alertscollector
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
+	"github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/exp/slices"
+
+	"github.com/appuio/alerts_exporter/internal/relabel"
 )
 
-func newDesc(labels []string) *prometheus.Desc {
+// DefaultMetricName is the name of the exported metric if AlertsCollector.MetricName is empty.
+const DefaultMetricName = "alerts_exporter_alerts"
+
+func (o *AlertsCollector) newDesc(labels []string) *prometheus.Desc {
 	return prometheus.NewDesc(
-		"alerts_exporter_alerts",
-		"Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.",
+		o.metricName(),
+		fmt.Sprintf("Alerts queried from the Alertmanager API. Alert state can be found in the '%s%s' label.", o.labelPrefix(), stateLabel),
 		labels,
-		nil,
+		o.ConstLabels,
 	)
 }
 
+// AlertSource provides the alerts exported by AlertsCollector.
+// It is implemented by the alert service of the Alertmanager API client.
+// Sources not backed by an Alertmanager ignore the query parameters they cannot apply.
+type AlertSource interface {
+	GetAlerts(params *alert.GetAlertsParams, opts ...alert.ClientOption) (*alert.GetAlertsOK, error)
+}
+
+var _ AlertSource = alert.ClientService(nil)
+
 type AlertsCollector struct {
-	AlertService alert.ClientService
+	AlertService AlertSource
 
 	WithInhibited, WithSilenced, WithUnprocessed, WithActive *bool
 
 	Filters []string
+	// Receiver is a regex matching the receivers of the alerts to query. Alerts of all receivers are queried if empty.
+	Receiver string
+	// WithReceivers adds a label listing the receivers each alert is routed to.
+	WithReceivers bool
+
+	// InvalidLabelStrategy decides how alert labels with invalid names are handled. Defaults to ReplaceInvalidChars.
+	InvalidLabelStrategy InvalidLabelStrategy
+
+	// AggregateBy enables the aggregation mode if set.
+	// Instead of one series per alert, the number of alerts grouped by these labels and the alert state is exported.
+	// Companion metrics of individual alerts are not exported in aggregation mode.
+	// Aggregation labels named like ConstLabels are exported as 'exported_<name>' with the value of the alert label.
+	AggregateBy []string
+
+	// RelabelConfigs are applied in order to the labels of each alert, including the labels added by the exporter.
+	// Alerts dropped by a relabel config are not exported.
+	// Alerts with identical labels after relabeling are exported as a single series whose value is the number of alerts.
+	RelabelConfigs []*relabel.Config
+
+	// ResolveSuppressors enables exporting info metrics describing the silences and inhibiting alerts of the exported alerts.
+	// The info metrics are joinable to the alert series by the silenced by and inhibited by labels.
+	ResolveSuppressors bool
+	// SilenceService is used to resolve silences if ResolveSuppressors is enabled.
+	// Silences are not resolved if nil.
+	SilenceService silence.ClientService
+
+	// MaxSeries limits the number of alert series exported per collection if greater than zero.
+	// The limit applies to the final series after sanitizing and relabeling.
+	// Series are kept by the SeverityPriority of their most important alert, then oldest first. The number of alerts of dropped series is exported in '<metric name>_truncated'.
+	// The limit does not apply in aggregation mode.
+	MaxSeries int
+	// SeverityPriority lists the values of the 'severity' label from most to least important. Defaults to DefaultSeverityPriority.
+	// Alerts with other severities are dropped first.
+	SeverityPriority []string
+
+	// WithTimestamps enables exporting the start, update and end timestamps of alerts as companion metrics with the same labels.
+	WithTimestamps bool
+
+	// Annotations is an allowlist of alert annotations to export.
+	// If set, the annotations are exported as a companion info metric and the alert series get a fingerprint label to join on.
+	Annotations []string
+
+	// LabelPrefix is the prefix of the labels added by the exporter. Defaults to DefaultLabelPrefix.
+	LabelPrefix string
+	// LabelCollisionPolicy decides how alert labels colliding with labels added by the exporter are handled. Defaults to PrefixOriginal.
+	LabelCollisionPolicy LabelCollisionPolicy
+
+	// MetricName is the name of the exported metric. Defaults to DefaultMetricName.
+	MetricName string
+	// Query is the name of the query run by the collector.
+	// If set, it is added as 'query' label to metrics describing the collector itself.
+	Query string
+
+	// ConstLabels are added to every exported series.
+	// Alert labels with the same name are renamed to 'exported_<name>'.
+	ConstLabels prometheus.Labels
+
+	stats *scrapeStats
 }
 
 var _ prometheus.Collector = &AlertsCollector{}
@@ -33,44 +109,174 @@ var _ prometheus.Collector = &AlertsCollector{}
 // Does not send any description and thus makes the collector unchecked.
 func (o *AlertsCollector) Describe(_ chan<- *prometheus.Desc) {}
 
+// Collect implements prometheus.Collector.
+// It queries the Alertmanager API and sends a metric for each alert.
+// Metrics describing the outcome of the query are always sent, the scrape does not fail if the query fails.
 func (o *AlertsCollector) Collect(ch chan<- prometheus.Metric) {
-	p := alert.NewGetAlertsParamsWithContext(context.Background()).
+	r, err := o.fetch(context.Background())
+	if err != nil {
+		log.Print("Error querying Alertmanager", err)
+		o.collectSelf(ch)
+		return
+	}
+
+	o.collectAlerts(ch, r)
+	o.collectSelf(ch)
+}
+
+// queryResult is the result of a successful query of the Alertmanager API.
+type queryResult struct {
+	alerts []*models.GettableAlert
+	// suppressors are the resolved silences and inhibiting alerts of the alerts. Nil if not resolved.
+	suppressors *suppressors
+}
+
+// fetch queries the Alertmanager API for alerts matching the collector's settings.
+// The outcome of the query is recorded in the collector's scrape statistics.
+// Failing to resolve suppressors is logged but does not fail the query.
+func (o *AlertsCollector) fetch(ctx context.Context) (_ queryResult, err error) {
+	defer func(start time.Time) { o.scrapeStats().record(start, err) }(time.Now())
+
+	p := alert.NewGetAlertsParamsWithContext(ctx).
 		WithActive(o.WithActive).
 		WithSilenced(o.WithSilenced).
 		WithUnprocessed(o.WithUnprocessed).
 		WithInhibited(o.WithInhibited).
 		WithFilter(o.Filters)
+	if o.Receiver != "" {
+		p = p.WithReceiver(&o.Receiver)
+	}
 
 	as, err := o.AlertService.GetAlerts(p)
-
 	if err != nil {
-		ch <- prometheus.NewInvalidMetric(newDesc([]string{}), err)
-		log.Print("Error querying Alertmanager", err)
+		return queryResult{}, err
+	}
+
+	r := queryResult{alerts: as.Payload}
+	if o.ResolveSuppressors {
+		r.suppressors, err = o.resolveSuppressors(ctx, as.Payload)
+		if err != nil {
+			log.Print("Error resolving alert suppressors", err)
+		}
+	}
+	return r, nil
+}
+
+// collectAlerts sends a metric for each of the given alerts.
+// Alerts with identical labels are exported as a single series whose value is the number of alerts.
+// The alerts are not modified and may be shared between concurrent calls.
+// Alerts that cannot be exported are skipped and counted in the metrics describing the collection.
+func (o *AlertsCollector) collectAlerts(ch chan<- prometheus.Metric, r queryResult) {
+	cs := &collectionStats{}
+	defer o.collectCollectionStats(ch, cs)
+
+	as := r.alerts
+	if len(o.AggregateBy) > 0 {
+		o.collectAggregated(ch, as, cs)
 		return
 	}
 
-	for _, a := range as.Payload {
-		if a.Status != nil {
-			if a.Status.State != nil {
-				a.Labels["_alerts_exporter_alert_state"] = *a.Status.State
-			}
-			if len(a.Status.InhibitedBy) > 0 {
-				a.Labels["_alerts_exporter_alert_inhibited_by"] = strings.Join(a.Status.InhibitedBy, ",")
+	if o.MaxSeries > 0 {
+		as = o.sortByPriority(as)
+	}
+	ss := o.alertSeries(as, cs)
+	if o.MaxSeries > 0 {
+		var dropped int
+		ss, dropped = o.truncate(ss)
+		o.collectTruncated(ch, dropped)
+	}
+
+	for _, s := range ss {
+		m, err := prometheus.NewConstMetric(
+			o.newDesc(s.keys),
+			prometheus.GaugeValue,
+			float64(len(s.alerts)),
+			s.values...,
+		)
+		if err != nil {
+			log.Print("Error exporting alert", err)
+			cs.exportErrors++
+			continue
+		}
+		ch <- m
+
+		if o.WithTimestamps {
+			if err := o.collectTimestamps(ch, s.alerts, s.keys, s.values); err != nil {
+				log.Print("Error exporting alert timestamps", err)
 			}
-			if len(a.Status.SilencedBy) > 0 {
-				a.Labels["_alerts_exporter_alert_silenced_by"] = strings.Join(a.Status.SilencedBy, ",")
+		}
+		if len(o.Annotations) > 0 {
+			for _, a := range s.alerts {
+				if err := o.collectAnnotations(ch, a); err != nil {
+					log.Print("Error exporting alert annotations", err)
+				}
 			}
 		}
+	}
 
-		k, v := pairs(a.Labels)
+	if r.suppressors != nil {
+		exported := make([]*models.GettableAlert, 0, len(as))
+		for _, s := range ss {
+			exported = append(exported, s.alerts...)
+		}
+		o.collectSuppressors(ch, exported, r.suppressors)
+	}
+}
 
-		ch <- prometheus.MustNewConstMetric(
-			newDesc(k),
-			prometheus.GaugeValue,
-			1,
-			v...,
-		)
+// alertLabels returns the labels of the series exported for the given alert.
+// ok is false if the alert should not be exported.
+// Alerts with invalid label names or colliding labels are counted in the given collection statistics.
+func (o *AlertsCollector) alertLabels(a *models.GettableAlert, cs *collectionStats) (_ map[string]string, ok bool) {
+	sanitized, affected, ok := sanitizeLabels(a.Labels, o.InvalidLabelStrategy)
+	if affected {
+		cs.invalidLabels++
+	}
+	if !ok {
+		return nil, false
+	}
+
+	labels := make(map[string]string, len(sanitized)+3)
+	for k, v := range sanitized {
+		if _, ok := o.ConstLabels[k]; ok {
+			k = "exported_" + k
+		}
+		labels[k] = v
+	}
+
+	collided, ok := o.addExporterLabels(labels, a)
+	if collided {
+		cs.labelCollisions++
+	}
+	if !ok {
+		return nil, false
+	}
+	return labels, relabel.Process(labels, o.RelabelConfigs...)
+}
+
+func (o *AlertsCollector) metricName() string {
+	if o.MetricName == "" {
+		return DefaultMetricName
+	}
+	return o.MetricName
+}
+
+// companionMetricName returns the name of a metric exported alongside the alert series.
+// It is derived from the singular of the metric name, for example 'alerts_exporter_alert_<suffix>'.
+func (o *AlertsCollector) companionMetricName(suffix string) string {
+	return strings.TrimSuffix(o.metricName(), "s") + "_" + suffix
+}
+
+// selfLabels returns the constant labels of metrics describing the collector itself.
+func (o *AlertsCollector) selfLabels() prometheus.Labels {
+	if o.Query == "" {
+		return o.ConstLabels
+	}
+	l := make(prometheus.Labels, len(o.ConstLabels)+1)
+	for k, v := range o.ConstLabels {
+		l[k] = v
 	}
+	l["query"] = o.Query
+	return l
 }
 
 func pairs(m map[string]string) (keys []string, values []string) {
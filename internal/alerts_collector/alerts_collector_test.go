@@ -3,9 +3,13 @@ package alertscollector_test
 import (
 	"context"
 	"errors"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
 	"github.com/golang/mock/gomock"
 	"github.com/prometheus/alertmanager/api/v2/client/alert"
 	"github.com/prometheus/alertmanager/api/v2/models"
@@ -14,6 +18,7 @@ import (
 
 	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
 	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+	"github.com/appuio/alerts_exporter/internal/relabel"
 )
 
 //go:generate go run github.com/golang/mock/mockgen -destination=./mock/alert_service.go -package mock github.com/prometheus/alertmanager/api/v2/client/alert ClientService
@@ -67,7 +72,291 @@ func TestAlertsCollector(t *testing.T) {
 # TYPE alerts_exporter_alerts gauge
 alerts_exporter_alerts{alertname="WhateverHappensHappens",severity="low"} 1
 alerts_exporter_alerts{_alerts_exporter_alert_inhibited_by="22a8bdd0-9b1e-4855-b8fb-8c1e18fa434f",_alerts_exporter_alert_silenced_by="d505b8d4-c5ce-466f-abd7-c704864299f5",_alerts_exporter_alert_state="active",alertname="ImportantAlert",severity="critical"} 1
+# HELP alerts_exporter_up Whether the last query to the Alertmanager API succeeded.
+# TYPE alerts_exporter_up gauge
+alerts_exporter_up 1
 `),
+			"alerts_exporter_alerts", "alerts_exporter_up",
+		),
+	)
+}
+
+func TestAlertsCollector_ConstLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{
+					Alert: models.Alert{
+						Labels: map[string]string{
+							"alertname":    "ImportantAlert",
+							"alertmanager": "prometheus-forwarded",
+						},
+					},
+				},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		ConstLabels: map[string]string{"alertmanager": "cluster-a"},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{alertmanager="cluster-a",alertname="ImportantAlert",exported_alertmanager="prometheus-forwarded"} 1
+`),
+			"alerts_exporter_alerts",
+		),
+	)
+}
+
+func TestAlertsCollector_RelabelConfigs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{
+					Alert: models.Alert{
+						Labels: map[string]string{
+							"alertname": "ImportantAlert",
+							"pod":       "app-0",
+						},
+					},
+				},
+				{
+					Alert: models.Alert{
+						Labels: map[string]string{
+							"alertname": "Watchdog",
+						},
+					},
+				},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		RelabelConfigs: []*relabel.Config{
+			{
+				SourceLabels: []string{"alertname"},
+				Regex:        relabel.MustNewRegex("Watchdog"),
+				Action:       relabel.Drop,
+			},
+			{
+				Regex:  relabel.MustNewRegex("pod"),
+				Action: relabel.LabelDrop,
+			},
+		},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{alertname="ImportantAlert"} 1
+`),
+			"alerts_exporter_alerts",
+		),
+	)
+}
+
+func TestAlertsCollector_RelabelConfigs_IdenticalLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	newAlert := func(pod string, startsAt int64) *models.GettableAlert {
+		return &models.GettableAlert{
+			Alert: models.Alert{
+				Labels: map[string]string{
+					"alertname": "PodCrashLooping",
+					"pod":       pod,
+				},
+			},
+			Fingerprint: ptr(pod),
+			StartsAt:    ptr(strfmt.DateTime(time.Unix(startsAt, 0))),
+		}
+	}
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				newAlert("app-0", 1700000300),
+				newAlert("app-1", 1700000000),
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		WithTimestamps: true,
+		RelabelConfigs: []*relabel.Config{
+			{
+				Regex:  relabel.MustNewRegex("pod"),
+				Action: relabel.LabelDrop,
+			},
+		},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{alertname="PodCrashLooping"} 2
+# HELP alerts_exporter_alert_starts_at_timestamp_seconds Unix timestamp at which alerts queried from the Alertmanager API started firing.
+# TYPE alerts_exporter_alert_starts_at_timestamp_seconds gauge
+alerts_exporter_alert_starts_at_timestamp_seconds{alertname="PodCrashLooping"} 1.7e+09
+`),
+			"alerts_exporter_alerts", "alerts_exporter_alert_starts_at_timestamp_seconds",
+		),
+	)
+}
+
+func TestAlertsCollector_ExportErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{Alert: models.Alert{Labels: map[string]string{"alertname": "ImportantAlert"}}},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		// Writing a const label results in a series that cannot be exported.
+		RelabelConfigs: []*relabel.Config{
+			{
+				SourceLabels: []string{"alertname"},
+				Separator:    relabel.DefaultSeparator,
+				Regex:        relabel.MustNewRegex(relabel.DefaultRegex),
+				TargetLabel:  "alertmanager",
+				Replacement:  relabel.DefaultReplacement,
+				Action:       relabel.Replace,
+			},
+		},
+		ConstLabels: map[string]string{"alertmanager": "cluster-a"},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts_export_errors Number of alert series that could not be exported in the last collection.
+# TYPE alerts_exporter_alerts_export_errors gauge
+alerts_exporter_alerts_export_errors{alertmanager="cluster-a"} 1
+# HELP alerts_exporter_alerts_invalid_labels Number of alerts with invalid label names in the last collection.
+# TYPE alerts_exporter_alerts_invalid_labels gauge
+alerts_exporter_alerts_invalid_labels{alertmanager="cluster-a"} 0
+`),
+			"alerts_exporter_alerts", "alerts_exporter_alerts_export_errors", "alerts_exporter_alerts_invalid_labels",
+		),
+	)
+}
+
+func TestAlertsCollector_Receiver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(p *alert.GetAlertsParams, _ ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+			require.Equal(t, "team-.*", *p.Receiver)
+			return &alert.GetAlertsOK{
+				Payload: []*models.GettableAlert{
+					{
+						Alert: models.Alert{
+							Labels: map[string]string{
+								"alertname": "ImportantAlert",
+							},
+						},
+						Receivers: []*models.Receiver{
+							{Name: ptr("team-b")},
+							{Name: ptr("team-a")},
+						},
+					},
+				},
+			}, nil
+		})
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		Receiver:      "team-.*",
+		WithReceivers: true,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{_alerts_exporter_alert_receivers="team-a,team-b",alertname="ImportantAlert"} 1
+`),
+			"alerts_exporter_alerts",
+		),
+	)
+}
+
+func TestAlertsCollector_MetricName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+
+	mockAlertService.
+		EXPECT().
+		GetAlerts(
+			gomock.Eq(alert.NewGetAlertsParamsWithContext(context.Background()).WithFilter([]string{`slo="true"`})),
+			gomock.Any(),
+		).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{
+					Alert: models.Alert{
+						Labels: map[string]string{
+							"alertname": "SLOBurnRate",
+						},
+					},
+				},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		Filters:    []string{`slo="true"`},
+		MetricName: "alerts_exporter_slo_alerts",
+		Query:      "slo",
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_slo_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_slo_alerts gauge
+alerts_exporter_slo_alerts{alertname="SLOBurnRate"} 1
+# HELP alerts_exporter_up Whether the last query to the Alertmanager API succeeded.
+# TYPE alerts_exporter_up gauge
+alerts_exporter_up{query="slo"} 1
+`),
+			"alerts_exporter_slo_alerts", "alerts_exporter_up",
 		),
 	)
 }
@@ -78,15 +367,42 @@ func TestAlertsCollector_Err(t *testing.T) {
 
 	mockAlertService := mock.NewMockClientService(ctrl)
 
-	mockAlertService.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(nil, errors.New("API error"))
+	mockAlertService.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(nil, errors.New("API error")).Times(2)
+	mockAlertService.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(nil, &runtime.APIError{Code: http.StatusUnauthorized})
+	mockAlertService.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(nil, alert.NewGetAlertsInternalServerError())
 
 	subject := &alertscollector.AlertsCollector{
 		AlertService: mockAlertService,
 	}
 
-	require.ErrorContains(t,
-		testutil.CollectAndCompare(subject, nil),
-		"API error",
+	for range 3 {
+		require.NoError(t,
+			testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_last_success_timestamp_seconds Unix timestamp of the last successful query to the Alertmanager API.
+# TYPE alerts_exporter_last_success_timestamp_seconds gauge
+alerts_exporter_last_success_timestamp_seconds 0
+# HELP alerts_exporter_up Whether the last query to the Alertmanager API succeeded.
+# TYPE alerts_exporter_up gauge
+alerts_exporter_up 0
+`),
+				"alerts_exporter_alerts", "alerts_exporter_up", "alerts_exporter_last_success_timestamp_seconds",
+			),
+		)
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_scrape_errors_total Number of failed queries to the Alertmanager API by error class.
+# TYPE alerts_exporter_scrape_errors_total counter
+alerts_exporter_scrape_errors_total{class="auth"} 1
+alerts_exporter_scrape_errors_total{class="connection"} 0
+alerts_exporter_scrape_errors_total{class="http_status"} 1
+alerts_exporter_scrape_errors_total{class="other"} 2
+alerts_exporter_scrape_errors_total{class="timeout"} 0
+alerts_exporter_scrape_errors_total{class="tls"} 0
+`),
+			"alerts_exporter_scrape_errors_total",
+		),
 	)
 }
 
@@ -0,0 +1,59 @@
+package alertscollector
+
+import (
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const fingerprintLabel = "alert_fingerprint"
+
+// collectAnnotations sends an info metric with the allowlisted annotations of the given alert.
+// The metric is joinable to the alert's series by the fingerprint label.
+func (o *AlertsCollector) collectAnnotations(ch chan<- prometheus.Metric, a *models.GettableAlert) error {
+	labels := map[string]string{
+		o.labelPrefix() + fingerprintLabel: deref(a.Fingerprint),
+	}
+	for _, key := range o.Annotations {
+		v, ok := a.Annotations[key]
+		if !ok {
+			continue
+		}
+		name := key
+		if !isValidLabelName(name) {
+			name = sanitizeLabelName(name)
+		}
+		if _, ok := o.ConstLabels[name]; ok {
+			name = "exported_" + name
+		}
+		if _, exists := labels[name]; exists || name == "" {
+			continue
+		}
+		labels[name] = v
+	}
+
+	k, v := pairs(labels)
+	m, err := prometheus.NewConstMetric(
+		prometheus.NewDesc(
+			o.companionMetricName("annotations_info"),
+			"Allowlisted annotations of alerts queried from the Alertmanager API. Joinable to the alert series by the '"+o.labelPrefix()+fingerprintLabel+"' label.",
+			k,
+			o.ConstLabels,
+		),
+		prometheus.GaugeValue,
+		1,
+		v...,
+	)
+	if err != nil {
+		return err
+	}
+	ch <- m
+	return nil
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,60 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+)
+
+func TestAlertsCollector_Annotations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{
+					Alert: models.Alert{
+						Labels: map[string]string{
+							"alertname": "ImportantAlert",
+						},
+					},
+					Annotations: map[string]string{
+						"summary":     "Something important happened",
+						"runbook_url": "https://runbooks.example.com/ImportantAlert",
+						"description": "Not exported",
+					},
+					Fingerprint: ptr("c2c2d4dbb1a4a0b2"),
+				},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		Annotations: []string{"summary", "runbook_url", "dashboard"},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{_alerts_exporter_alert_fingerprint="c2c2d4dbb1a4a0b2",alertname="ImportantAlert"} 1
+# HELP alerts_exporter_alert_annotations_info Allowlisted annotations of alerts queried from the Alertmanager API. Joinable to the alert series by the '_alerts_exporter_alert_fingerprint' label.
+# TYPE alerts_exporter_alert_annotations_info gauge
+alerts_exporter_alert_annotations_info{_alerts_exporter_alert_fingerprint="c2c2d4dbb1a4a0b2",runbook_url="https://runbooks.example.com/ImportantAlert",summary="Something important happened"} 1
+`),
+			"alerts_exporter_alerts", "alerts_exporter_alert_annotations_info",
+		),
+	)
+}
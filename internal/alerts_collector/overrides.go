@@ -0,0 +1,107 @@
+package alertscollector
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+)
+
+// Names of the URL query parameters overriding collector settings.
+const (
+	OverrideFilter      = "filter"
+	OverrideActive      = "active"
+	OverrideSilenced    = "silenced"
+	OverrideInhibited   = "inhibited"
+	OverrideUnprocessed = "unprocessed"
+	OverrideReceiver    = "receiver"
+)
+
+// OverrideNames lists all parameters that can override collector settings.
+var OverrideNames = []string{OverrideFilter, OverrideActive, OverrideSilenced, OverrideInhibited, OverrideUnprocessed, OverrideReceiver}
+
+// Overrides are request scoped settings replacing the settings of an AlertsCollector.
+// Nil fields do not override the collector's settings.
+type Overrides struct {
+	Filters  []string
+	Receiver *string
+
+	WithInhibited, WithSilenced, WithUnprocessed, WithActive *bool
+}
+
+// ParseOverrides parses overrides from the given URL query parameters.
+// Parameters not in OverrideNames are ignored.
+// An error is returned if a parameter is set that is not in allowed or if a state parameter is not a boolean.
+func ParseOverrides(q url.Values, allowed []string) (Overrides, error) {
+	var o Overrides
+	for _, name := range OverrideNames {
+		vs, ok := q[name]
+		if !ok {
+			continue
+		}
+		if !slices.Contains(allowed, name) {
+			return Overrides{}, fmt.Errorf("overriding %q is not allowed", name)
+		}
+		switch name {
+		case OverrideFilter:
+			o.Filters = vs
+			continue
+		case OverrideReceiver:
+			r := vs[len(vs)-1]
+			if _, err := regexp.Compile(r); err != nil {
+				return Overrides{}, fmt.Errorf("invalid value for %q: %w", name, err)
+			}
+			o.Receiver = &r
+			continue
+		}
+
+		b, err := strconv.ParseBool(vs[len(vs)-1])
+		if err != nil {
+			return Overrides{}, fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+		switch name {
+		case OverrideActive:
+			o.WithActive = &b
+		case OverrideSilenced:
+			o.WithSilenced = &b
+		case OverrideInhibited:
+			o.WithInhibited = &b
+		case OverrideUnprocessed:
+			o.WithUnprocessed = &b
+		}
+	}
+	return o, nil
+}
+
+// IsZero returns true if no setting is overridden.
+func (o Overrides) IsZero() bool {
+	return o.Filters == nil && o.Receiver == nil && o.WithActive == nil && o.WithSilenced == nil && o.WithInhibited == nil && o.WithUnprocessed == nil
+}
+
+// Apply returns a copy of the given collector with the overrides applied.
+// The copy does not share scrape statistics with the given collector.
+func (o Overrides) Apply(c *AlertsCollector) *AlertsCollector {
+	cc := *c
+	cc.stats = nil
+	if o.Filters != nil {
+		cc.Filters = o.Filters
+	}
+	if o.Receiver != nil {
+		cc.Receiver = *o.Receiver
+	}
+	if o.WithActive != nil {
+		cc.WithActive = o.WithActive
+	}
+	if o.WithSilenced != nil {
+		cc.WithSilenced = o.WithSilenced
+	}
+	if o.WithInhibited != nil {
+		cc.WithInhibited = o.WithInhibited
+	}
+	if o.WithUnprocessed != nil {
+		cc.WithUnprocessed = o.WithUnprocessed
+	}
+	return &cc
+}
@@ -0,0 +1,72 @@
+package alertscollector_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+)
+
+func TestParseOverrides(t *testing.T) {
+	o, err := alertscollector.ParseOverrides(url.Values{
+		"filter":   {`severity="critical"`, `slo="true"`},
+		"silenced": {"false"},
+		"target":   {"ignored"},
+	}, alertscollector.OverrideNames)
+	require.NoError(t, err)
+	require.Equal(t, alertscollector.Overrides{
+		Filters:      []string{`severity="critical"`, `slo="true"`},
+		WithSilenced: ptr(false),
+	}, o)
+
+	c := o.Apply(&alertscollector.AlertsCollector{
+		WithActive:   ptr(true),
+		WithSilenced: ptr(true),
+		Filters:      []string{`team="a"`},
+	})
+	require.Equal(t, &alertscollector.AlertsCollector{
+		WithActive:   ptr(true),
+		WithSilenced: ptr(false),
+		Filters:      []string{`severity="critical"`, `slo="true"`},
+	}, c)
+}
+
+func TestParseOverrides_Receiver(t *testing.T) {
+	o, err := alertscollector.ParseOverrides(url.Values{
+		"receiver": {"team-a|team-b"},
+	}, []string{alertscollector.OverrideReceiver})
+	require.NoError(t, err)
+	require.False(t, o.IsZero())
+
+	c := o.Apply(&alertscollector.AlertsCollector{
+		Receiver: "team-.*",
+	})
+	require.Equal(t, "team-a|team-b", c.Receiver)
+
+	_, err = alertscollector.ParseOverrides(url.Values{
+		"receiver": {"team-("},
+	}, []string{alertscollector.OverrideReceiver})
+	require.ErrorContains(t, err, `invalid value for "receiver"`)
+}
+
+func TestParseOverrides_Empty(t *testing.T) {
+	o, err := alertscollector.ParseOverrides(url.Values{}, nil)
+	require.NoError(t, err)
+	require.True(t, o.IsZero())
+}
+
+func TestParseOverrides_NotAllowed(t *testing.T) {
+	_, err := alertscollector.ParseOverrides(url.Values{
+		"filter": {`severity="critical"`},
+	}, []string{alertscollector.OverrideSilenced})
+	require.ErrorContains(t, err, `overriding "filter" is not allowed`)
+}
+
+func TestParseOverrides_InvalidBool(t *testing.T) {
+	_, err := alertscollector.ParseOverrides(url.Values{
+		"active": {"maybe"},
+	}, alertscollector.OverrideNames)
+	require.ErrorContains(t, err, `invalid value for "active"`)
+}
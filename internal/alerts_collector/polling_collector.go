@@ -0,0 +1,134 @@
+package alertscollector
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+)
+
+func newSnapshotAgeDesc(constLabels prometheus.Labels) *prometheus.Desc {
+	return prometheus.NewDesc(
+		"alerts_exporter_snapshot_age_seconds",
+		"Seconds since the served alerts were last successfully queried from the Alertmanager API.",
+		nil,
+		constLabels,
+	)
+}
+
+// NewPollingAlertsCollector creates a new PollingAlertsCollector.
+// PollingAlertsCollector queries the Alertmanager API in the background every interval using the settings of the given collector.
+// Scrapes are served from the last successfully queried snapshot.
+// If interval is 0, it defaults to 30 seconds.
+func NewPollingAlertsCollector(c *AlertsCollector, interval time.Duration) *PollingAlertsCollector {
+	if interval == 0 {
+		interval = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &PollingAlertsCollector{
+		collector: c,
+		ticker:    time.NewTicker(interval),
+		cancel:    cancel,
+	}
+
+	go func() {
+		p.refresh(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.ticker.C:
+				p.refresh(ctx)
+			}
+		}
+	}()
+
+	return p
+}
+
+// PollingAlertsCollector serves alerts from an in-memory snapshot refreshed in the background.
+type PollingAlertsCollector struct {
+	collector *AlertsCollector
+
+	ticker *time.Ticker
+	cancel context.CancelFunc
+
+	inflight singleflight.Group
+
+	mu         sync.RWMutex
+	snapshot   queryResult
+	snapshotAt time.Time
+}
+
+var _ prometheus.Collector = &PollingAlertsCollector{}
+
+// Describe implements prometheus.Collector.
+// Does not send any description and thus makes the collector unchecked.
+func (p *PollingAlertsCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+// It sends the alerts from the last snapshot, the age of the snapshot and the outcome of the last refresh.
+// If no snapshot has been taken yet, it waits for the in-flight refresh or starts one.
+func (p *PollingAlertsCollector) Collect(ch chan<- prometheus.Metric) {
+	r, at := p.load()
+	if at.IsZero() {
+		p.initialRefresh(context.Background())
+		r, at = p.load()
+	}
+	if at.IsZero() {
+		p.collector.collectSelf(ch)
+		return
+	}
+
+	p.collector.collectAlerts(ch, r)
+	p.collector.collectSelf(ch)
+	ch <- prometheus.MustNewConstMetric(newSnapshotAgeDesc(p.collector.selfLabels()), prometheus.GaugeValue, time.Since(at).Seconds())
+}
+
+// Stop stops the background refresh.
+func (p *PollingAlertsCollector) Stop() {
+	p.cancel()
+	p.ticker.Stop()
+}
+
+// refresh queries the Alertmanager API and stores the result as the new snapshot.
+// Concurrent calls share a single in-flight request.
+// On error the previous snapshot is kept.
+func (p *PollingAlertsCollector) refresh(ctx context.Context) {
+	p.inflight.Do("", func() (any, error) {
+		p.store(p.collector.fetch(ctx))
+		return nil, nil
+	})
+}
+
+// initialRefresh is like refresh but does not query the Alertmanager API if a snapshot was taken in the meantime.
+func (p *PollingAlertsCollector) initialRefresh(ctx context.Context) {
+	p.inflight.Do("", func() (any, error) {
+		if _, at := p.load(); !at.IsZero() {
+			return nil, nil
+		}
+		p.store(p.collector.fetch(ctx))
+		return nil, nil
+	})
+}
+
+func (p *PollingAlertsCollector) store(r queryResult, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		log.Print("Error querying Alertmanager", err)
+		return
+	}
+	p.snapshot = r
+	p.snapshotAt = time.Now()
+}
+
+func (p *PollingAlertsCollector) load() (queryResult, time.Time) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.snapshot, p.snapshotAt
+}
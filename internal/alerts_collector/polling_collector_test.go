@@ -0,0 +1,72 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+)
+
+func TestPollingAlertsCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+
+	release := make(chan struct{})
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(*alert.GetAlertsParams, ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+			<-release
+			return &alert.GetAlertsOK{
+				Payload: []*models.GettableAlert{
+					{
+						Alert: models.Alert{
+							Labels: map[string]string{
+								"alertname": "ImportantAlert",
+							},
+						},
+						Status: &models.AlertStatus{
+							State: ptr("active"),
+						},
+					},
+				},
+			}, nil
+		}).
+		Times(1)
+
+	subject := alertscollector.NewPollingAlertsCollector(&alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+	}, time.Hour)
+	defer subject.Stop()
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t,
+				testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{_alerts_exporter_alert_state="active",alertname="ImportantAlert"} 1
+`),
+					"alerts_exporter_alerts",
+				),
+			)
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, 1, testutil.CollectAndCount(subject, "alerts_exporter_snapshot_age_seconds"))
+}
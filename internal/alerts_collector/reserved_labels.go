@@ -0,0 +1,111 @@
+package alertscollector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultLabelPrefix is the prefix of the labels added by the exporter if AlertsCollector.LabelPrefix is empty.
+const DefaultLabelPrefix = "_alerts_exporter_"
+
+// Names of the labels added by the exporter, without prefix.
+const (
+	stateLabel       = "alert_state"
+	inhibitedByLabel = "alert_inhibited_by"
+	silencedByLabel  = "alert_silenced_by"
+	receiversLabel   = "alert_receivers"
+)
+
+// LabelCollisionPolicy decides how alert labels are handled that have the same name as a label added by the exporter.
+type LabelCollisionPolicy string
+
+const (
+	// PrefixOriginal renames the alert label to 'exported_<name>'.
+	PrefixOriginal LabelCollisionPolicy = "prefix-original"
+	// KeepOriginal keeps the alert label and does not add the exporter's label.
+	KeepOriginal LabelCollisionPolicy = "keep-original"
+	// DropCollidingAlert drops alerts with colliding labels.
+	DropCollidingAlert LabelCollisionPolicy = "drop-alert"
+)
+
+// ParseLabelCollisionPolicy parses the given label collision policy.
+// An empty string defaults to PrefixOriginal.
+func ParseLabelCollisionPolicy(s string) (LabelCollisionPolicy, error) {
+	switch p := LabelCollisionPolicy(s); p {
+	case "":
+		return PrefixOriginal, nil
+	case PrefixOriginal, KeepOriginal, DropCollidingAlert:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown label collision policy %q", s)
+}
+
+// ValidateLabelPrefix returns an error if the given prefix does not result in valid label names.
+func ValidateLabelPrefix(prefix string) error {
+	if !isValidLabelName(prefix + stateLabel) {
+		return fmt.Errorf("label prefix %q does not result in valid label names", prefix)
+	}
+	return nil
+}
+
+func (o *AlertsCollector) labelPrefix() string {
+	if o.LabelPrefix == "" {
+		return DefaultLabelPrefix
+	}
+	return o.LabelPrefix
+}
+
+// addExporterLabels adds the labels describing the alert's status to the given labels according to the collector's collision policy.
+// collided is true if any alert label collided with an exporter label. ok is false if the alert should be dropped.
+func (o *AlertsCollector) addExporterLabels(labels map[string]string, a *models.GettableAlert) (collided, ok bool) {
+	add := make(map[string]string, 4)
+	if len(o.Annotations) > 0 {
+		add[fingerprintLabel] = deref(a.Fingerprint)
+	}
+	if o.WithReceivers {
+		names := make([]string, 0, len(a.Receivers))
+		for _, r := range a.Receivers {
+			if r != nil {
+				names = append(names, deref(r.Name))
+			}
+		}
+		slices.Sort(names)
+		add[receiversLabel] = strings.Join(names, ",")
+	}
+	if a.Status != nil {
+		if a.Status.State != nil {
+			add[stateLabel] = *a.Status.State
+		}
+		if len(a.Status.InhibitedBy) > 0 {
+			add[inhibitedByLabel] = strings.Join(a.Status.InhibitedBy, ",")
+		}
+		if len(a.Status.SilencedBy) > 0 {
+			add[silencedByLabel] = strings.Join(a.Status.SilencedBy, ",")
+		}
+	}
+
+	prefix := o.labelPrefix()
+	for name := range add {
+		if _, exists := labels[prefix+name]; exists {
+			collided = true
+		}
+	}
+	if collided && o.LabelCollisionPolicy == DropCollidingAlert {
+		return true, false
+	}
+
+	for name, v := range add {
+		k := prefix + name
+		if orig, exists := labels[k]; exists {
+			if o.LabelCollisionPolicy == KeepOriginal {
+				continue
+			}
+			labels["exported_"+k] = orig
+		}
+		labels[k] = v
+	}
+	return collided, true
+}
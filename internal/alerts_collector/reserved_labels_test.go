@@ -0,0 +1,96 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+)
+
+func TestAlertsCollector_LabelCollisions(t *testing.T) {
+	for policy, expected := range map[alertscollector.LabelCollisionPolicy]string{
+		alertscollector.PrefixOriginal: `
+alerts_exporter_alerts{alertname="CollidingAlert",am_alert_state="active",exported_am_alert_state="custom"} 1
+alerts_exporter_alerts{alertname="NormalAlert",am_alert_state="active"} 1
+`,
+		alertscollector.KeepOriginal: `
+alerts_exporter_alerts{alertname="CollidingAlert",am_alert_state="custom"} 1
+alerts_exporter_alerts{alertname="NormalAlert",am_alert_state="active"} 1
+`,
+		alertscollector.DropCollidingAlert: `
+alerts_exporter_alerts{alertname="NormalAlert",am_alert_state="active"} 1
+`,
+	} {
+		t.Run(string(policy), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockAlertService := mock.NewMockClientService(ctrl)
+			mockAlertService.
+				EXPECT().
+				GetAlerts(gomock.Any(), gomock.Any()).
+				Return(&alert.GetAlertsOK{
+					Payload: []*models.GettableAlert{
+						{
+							Alert: models.Alert{
+								Labels: map[string]string{
+									"alertname":      "CollidingAlert",
+									"am_alert_state": "custom",
+								},
+							},
+							Status: &models.AlertStatus{
+								State: ptr("active"),
+							},
+						},
+						{
+							Alert: models.Alert{
+								Labels: map[string]string{
+									"alertname": "NormalAlert",
+								},
+							},
+							Status: &models.AlertStatus{
+								State: ptr("active"),
+							},
+						},
+					},
+				}, nil).
+				Times(2)
+
+			subject := &alertscollector.AlertsCollector{
+				AlertService: mockAlertService,
+
+				LabelPrefix:          "am_",
+				LabelCollisionPolicy: policy,
+			}
+
+			// Collecting the same alerts again must not increase the count.
+			for range 2 {
+				require.NoError(t,
+					testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the 'am_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+`+expected+`
+# HELP alerts_exporter_alerts_label_collisions Number of alerts with labels colliding with labels added by the exporter in the last collection.
+# TYPE alerts_exporter_alerts_label_collisions gauge
+alerts_exporter_alerts_label_collisions 1
+`),
+						"alerts_exporter_alerts", "alerts_exporter_alerts_label_collisions",
+					),
+				)
+			}
+		})
+	}
+}
+
+func TestValidateLabelPrefix(t *testing.T) {
+	require.NoError(t, alertscollector.ValidateLabelPrefix(alertscollector.DefaultLabelPrefix))
+	require.NoError(t, alertscollector.ValidateLabelPrefix(""))
+	require.Error(t, alertscollector.ValidateLabelPrefix("__"))
+	require.Error(t, alertscollector.ValidateLabelPrefix("alerts-exporter-"))
+}
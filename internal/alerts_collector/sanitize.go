@@ -0,0 +1,108 @@
+package alertscollector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/exp/slices"
+)
+
+// InvalidLabelStrategy decides how alert labels are handled whose names are not valid Prometheus label names.
+type InvalidLabelStrategy string
+
+const (
+	// ReplaceInvalidChars replaces invalid characters in label names with underscores.
+	// Labels whose sanitized names collide with another label are dropped.
+	ReplaceInvalidChars InvalidLabelStrategy = "replace"
+	// DropInvalidLabel drops labels with invalid names.
+	DropInvalidLabel InvalidLabelStrategy = "drop-label"
+	// DropInvalidAlert drops alerts with any invalid label name.
+	DropInvalidAlert InvalidLabelStrategy = "drop-alert"
+)
+
+// ParseInvalidLabelStrategy parses the given invalid label strategy.
+// An empty string defaults to ReplaceInvalidChars.
+func ParseInvalidLabelStrategy(s string) (InvalidLabelStrategy, error) {
+	switch st := InvalidLabelStrategy(s); st {
+	case "":
+		return ReplaceInvalidChars, nil
+	case ReplaceInvalidChars, DropInvalidLabel, DropInvalidAlert:
+		return st, nil
+	}
+	return "", fmt.Errorf("unknown invalid label strategy %q", s)
+}
+
+// sanitizeLabels returns a copy of the given labels with valid label names and values according to the strategy.
+// Invalid UTF-8 in label values is always replaced.
+// affected is true if any label name was invalid. ok is false if the alert should be dropped.
+func sanitizeLabels(labels map[string]string, strategy InvalidLabelStrategy) (sanitized map[string]string, affected, ok bool) {
+	sanitized = make(map[string]string, len(labels))
+
+	var invalid []string
+	for k, v := range labels {
+		v = strings.ToValidUTF8(v, "\uFFFD")
+		if !isValidLabelName(k) {
+			invalid = append(invalid, k)
+			continue
+		}
+		sanitized[k] = v
+	}
+	if len(invalid) == 0 {
+		return sanitized, false, true
+	}
+
+	switch strategy {
+	case DropInvalidAlert:
+		return nil, true, false
+	case DropInvalidLabel:
+		return sanitized, true, true
+	}
+
+	// Sort to deterministically decide which label is dropped on collisions.
+	slices.Sort(invalid)
+	for _, k := range invalid {
+		s := sanitizeLabelName(k)
+		if _, exists := sanitized[s]; exists || s == "" {
+			continue
+		}
+		sanitized[s] = strings.ToValidUTF8(labels[k], "\uFFFD")
+	}
+	return sanitized, true, true
+}
+
+// isValidLabelName returns true if the given name is a valid, non-reserved Prometheus label name.
+func isValidLabelName(name string) bool {
+	return model.LabelName(name).IsValidLegacy() && !strings.HasPrefix(name, model.ReservedLabelPrefix)
+}
+
+// sanitizeLabelName replaces invalid characters in the given label name with underscores.
+// Names starting with a digit are prefixed with an underscore and the reserved '__' prefix is shortened to a single underscore.
+// An empty string is returned if no valid name can be derived.
+func sanitizeLabelName(name string) string {
+	if name == "" {
+		return ""
+	}
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	s := b.String()
+	if strings.HasPrefix(s, model.ReservedLabelPrefix) {
+		s = "_" + strings.TrimLeft(s, "_")
+	}
+	if !isValidLabelName(s) {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+)
+
+func TestAlertsCollector_InvalidLabels(t *testing.T) {
+	for strategy, expected := range map[alertscollector.InvalidLabelStrategy]string{
+		alertscollector.ReplaceInvalidChars: `
+alerts_exporter_alerts{_1st="y",_meta="z",alertname="OddAlert",app_kubernetes_io_name="x"} 1
+alerts_exporter_alerts{alertname="OddAlert",app_kubernetes_io_name="other"} 1
+alerts_exporter_alerts{alertname="NormalAlert"} 1
+`,
+		alertscollector.DropInvalidLabel: `
+alerts_exporter_alerts{alertname="OddAlert"} 2
+alerts_exporter_alerts{alertname="NormalAlert"} 1
+`,
+		alertscollector.DropInvalidAlert: `
+alerts_exporter_alerts{alertname="NormalAlert"} 1
+`,
+	} {
+		t.Run(string(strategy), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			mockAlertService := mock.NewMockClientService(ctrl)
+			mockAlertService.
+				EXPECT().
+				GetAlerts(gomock.Any(), gomock.Any()).
+				Return(&alert.GetAlertsOK{
+					Payload: []*models.GettableAlert{
+						{
+							Alert: models.Alert{
+								Labels: map[string]string{
+									"alertname":              "OddAlert",
+									"app.kubernetes.io/name": "x",
+									"1st":                    "y",
+									"__meta":                 "z",
+								},
+							},
+						},
+						{
+							Alert: models.Alert{
+								Labels: map[string]string{
+									"alertname":              "OddAlert",
+									"app.kubernetes.io/name": "other",
+								},
+							},
+						},
+						{
+							Alert: models.Alert{
+								Labels: map[string]string{
+									"alertname": "NormalAlert",
+								},
+							},
+						},
+					},
+				}, nil).
+				Times(2)
+
+			subject := &alertscollector.AlertsCollector{
+				AlertService: mockAlertService,
+
+				InvalidLabelStrategy: strategy,
+			}
+
+			// Collecting the same alerts again must not increase the count.
+			for range 2 {
+				require.NoError(t,
+					testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+`+expected+`
+# HELP alerts_exporter_alerts_invalid_labels Number of alerts with invalid label names in the last collection.
+# TYPE alerts_exporter_alerts_invalid_labels gauge
+alerts_exporter_alerts_invalid_labels 2
+`),
+						"alerts_exporter_alerts", "alerts_exporter_alerts_invalid_labels",
+					),
+				)
+			}
+		})
+	}
+}
+
+func TestParseInvalidLabelStrategy(t *testing.T) {
+	s, err := alertscollector.ParseInvalidLabelStrategy("")
+	require.NoError(t, err)
+	require.Equal(t, alertscollector.ReplaceInvalidChars, s)
+
+	s, err = alertscollector.ParseInvalidLabelStrategy("drop-alert")
+	require.NoError(t, err)
+	require.Equal(t, alertscollector.DropInvalidAlert, s)
+
+	_, err = alertscollector.ParseInvalidLabelStrategy("random")
+	require.Error(t, err)
+}
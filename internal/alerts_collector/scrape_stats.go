@@ -0,0 +1,164 @@
+package alertscollector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Error classes of failed Alertmanager API queries.
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassAuth       = "auth"
+	ErrorClassTLS        = "tls"
+	ErrorClassHTTPStatus = "http_status"
+	ErrorClassConnection = "connection"
+	ErrorClassOther      = "other"
+)
+
+var errorClasses = []string{ErrorClassTimeout, ErrorClassAuth, ErrorClassTLS, ErrorClassHTTPStatus, ErrorClassConnection, ErrorClassOther}
+
+// scrapeStats records the outcome of Alertmanager API queries.
+type scrapeStats struct {
+	mu          sync.Mutex
+	up          bool
+	duration    time.Duration
+	lastSuccess time.Time
+	errors      map[string]int
+}
+
+// statsMu guards the lazy initialization of AlertsCollector.stats.
+var statsMu sync.Mutex
+
+// scrapeStats returns the collector's scrape statistics, initializing them on first use.
+func (o *AlertsCollector) scrapeStats() *scrapeStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	if o.stats == nil {
+		o.stats = &scrapeStats{errors: make(map[string]int, len(errorClasses))}
+	}
+	return o.stats
+}
+
+func (s *scrapeStats) record(start time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.duration = time.Since(start)
+	s.up = err == nil
+	if err != nil {
+		s.errors[classifyError(err)]++
+		return
+	}
+	s.lastSuccess = time.Now()
+}
+
+// collectSelf sends the metrics describing the outcome of the last Alertmanager API query.
+func (o *AlertsCollector) collectSelf(ch chan<- prometheus.Metric) {
+	s := o.scrapeStats()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := o.selfLabels()
+
+	up := 0.0
+	if s.up {
+		up = 1
+	}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_up", "Whether the last query to the Alertmanager API succeeded.", nil, l),
+		prometheus.GaugeValue,
+		up,
+	)
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_scrape_duration_seconds", "Duration of the last query to the Alertmanager API.", nil, l),
+		prometheus.GaugeValue,
+		s.duration.Seconds(),
+	)
+	lastSuccess := 0.0
+	if !s.lastSuccess.IsZero() {
+		lastSuccess = float64(s.lastSuccess.UnixNano()) / 1e9
+	}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_last_success_timestamp_seconds", "Unix timestamp of the last successful query to the Alertmanager API.", nil, l),
+		prometheus.GaugeValue,
+		lastSuccess,
+	)
+	errDesc := prometheus.NewDesc("alerts_exporter_scrape_errors_total", "Number of failed queries to the Alertmanager API by error class.", []string{"class"}, l)
+	for _, c := range errorClasses {
+		ch <- prometheus.MustNewConstMetric(errDesc, prometheus.CounterValue, float64(s.errors[c]), c)
+	}
+}
+
+// collectionStats counts the alerts affected by label handling during a single collection.
+// Alerts are counted once per collection so the counts do not depend on how often the same alerts are collected.
+type collectionStats struct {
+	// invalidLabels is the number of alerts with invalid label names.
+	invalidLabels int
+	// labelCollisions is the number of alerts with labels colliding with labels added by the exporter.
+	labelCollisions int
+	// exportErrors is the number of series that could not be exported.
+	exportErrors int
+}
+
+// collectCollectionStats sends the metrics describing the given collection.
+func (o *AlertsCollector) collectCollectionStats(ch chan<- prometheus.Metric, cs *collectionStats) {
+	l := o.selfLabels()
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_alerts_invalid_labels", "Number of alerts with invalid label names in the last collection.", nil, l),
+		prometheus.GaugeValue,
+		float64(cs.invalidLabels),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_alerts_label_collisions", "Number of alerts with labels colliding with labels added by the exporter in the last collection.", nil, l),
+		prometheus.GaugeValue,
+		float64(cs.labelCollisions),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_alerts_export_errors", "Number of alert series that could not be exported in the last collection.", nil, l),
+		prometheus.GaugeValue,
+		float64(cs.exportErrors),
+	)
+}
+
+// classifyError returns the error class of the given Alertmanager API query error.
+func classifyError(err error) string {
+	var statusErr interface{ IsCode(int) bool }
+	if errors.As(err, &statusErr) {
+		if statusErr.IsCode(http.StatusUnauthorized) || statusErr.IsCode(http.StatusForbidden) {
+			return ErrorClassAuth
+		}
+		return ErrorClassHTTPStatus
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+
+	var (
+		verificationErr *tls.CertificateVerificationError
+		recordHeaderErr tls.RecordHeaderError
+		alertErr        tls.AlertError
+		unknownAuthErr  x509.UnknownAuthorityError
+		hostnameErr     x509.HostnameError
+		certInvalidErr  x509.CertificateInvalidError
+	)
+	if errors.As(err, &verificationErr) || errors.As(err, &recordHeaderErr) || errors.As(err, &alertErr) ||
+		errors.As(err, &unknownAuthErr) || errors.As(err, &hostnameErr) || errors.As(err, &certInvalidErr) {
+		return ErrorClassTLS
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorClassConnection
+	}
+
+	return ErrorClassOther
+}
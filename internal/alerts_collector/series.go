@@ -0,0 +1,38 @@
+package alertscollector
+
+import (
+	"strings"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// series is an exported alert series.
+// Alerts with identical labels, for example after relabeling or dropping invalid labels, share a series.
+type series struct {
+	keys, values []string
+	// alerts are the alerts exported in the series in order of appearance.
+	alerts []*models.GettableAlert
+}
+
+// alertSeries returns the series of the given alerts in order of first appearance.
+// Alerts that cannot be exported are skipped and counted in the given collection statistics.
+func (o *AlertsCollector) alertSeries(as []*models.GettableAlert, cs *collectionStats) []*series {
+	ss := make([]*series, 0, len(as))
+	byLabels := make(map[string]*series, len(as))
+	for _, a := range as {
+		labels, ok := o.alertLabels(a, cs)
+		if !ok {
+			continue
+		}
+		k, v := pairs(labels)
+		key := strings.Join(k, "\xff") + "\xfe" + strings.Join(v, "\xff")
+		if s, ok := byLabels[key]; ok {
+			s.alerts = append(s.alerts, a)
+			continue
+		}
+		s := &series{keys: k, values: v, alerts: []*models.GettableAlert{a}}
+		byLabels[key] = s
+		ss = append(ss, s)
+	}
+	return ss
+}
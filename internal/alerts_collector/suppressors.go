@@ -0,0 +1,151 @@
+package alertscollector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// commentPrefixLength is the maximum number of characters of silence comments exported.
+const commentPrefixLength = 64
+
+// suppressors are the silences and inhibiting alerts of queried alerts.
+type suppressors struct {
+	// silences by ID.
+	silences map[string]*models.GettableSilence
+	// alertnames of inhibiting alerts by fingerprint.
+	alertnames map[string]string
+}
+
+// resolveSuppressors looks up the silences and inhibiting alerts referenced by the given alerts.
+// Inhibiting alerts not in the given alerts are queried without the collector's filters.
+// Silences are not resolved if the collector has no SilenceService.
+func (o *AlertsCollector) resolveSuppressors(ctx context.Context, as []*models.GettableAlert) (*suppressors, error) {
+	s := &suppressors{
+		silences:   make(map[string]*models.GettableSilence),
+		alertnames: make(map[string]string),
+	}
+
+	var silenced, missing bool
+	known := make(map[string]string, len(as))
+	for _, a := range as {
+		known[deref(a.Fingerprint)] = a.Labels["alertname"]
+	}
+	for _, a := range as {
+		if a.Status == nil {
+			continue
+		}
+		silenced = silenced || len(a.Status.SilencedBy) > 0
+		for _, fp := range a.Status.InhibitedBy {
+			name, ok := known[fp]
+			if !ok {
+				missing = true
+				continue
+			}
+			s.alertnames[fp] = name
+		}
+	}
+
+	if missing {
+		all, err := o.AlertService.GetAlerts(alert.NewGetAlertsParamsWithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query inhibiting alerts: %w", err)
+		}
+		for _, a := range all.Payload {
+			s.alertnames[deref(a.Fingerprint)] = a.Labels["alertname"]
+		}
+	}
+
+	if silenced && o.SilenceService != nil {
+		ss, err := o.SilenceService.GetSilences(silence.NewGetSilencesParamsWithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query silences: %w", err)
+		}
+		for _, sil := range ss.Payload {
+			s.silences[deref(sil.ID)] = sil
+		}
+	}
+
+	return s, nil
+}
+
+// collectSuppressors sends info metrics describing the silences and inhibiting alerts of the given alerts.
+// One metric is sent per distinct value of the silenced by and inhibited by labels, making them joinable to the alert series.
+func (o *AlertsCollector) collectSuppressors(ch chan<- prometheus.Metric, as []*models.GettableAlert, s *suppressors) {
+	silencedByKey := o.labelPrefix() + silencedByLabel
+	inhibitedByKey := o.labelPrefix() + inhibitedByLabel
+	silencedByDesc := prometheus.NewDesc(
+		o.companionMetricName("silenced_by_info"),
+		"Creators and comments of the silences of alerts queried from the Alertmanager API. Joinable to the alert series by the '"+silencedByKey+"' label.",
+		[]string{silencedByKey, "created_by", "comment"},
+		o.ConstLabels,
+	)
+	inhibitedByDesc := prometheus.NewDesc(
+		o.companionMetricName("inhibited_by_info"),
+		"Names of the alerts inhibiting alerts queried from the Alertmanager API. Joinable to the alert series by the '"+inhibitedByKey+"' label.",
+		[]string{inhibitedByKey, "alertname"},
+		o.ConstLabels,
+	)
+
+	seenSilencedBy := make(map[string]struct{})
+	seenInhibitedBy := make(map[string]struct{})
+	for _, a := range as {
+		if a.Status == nil {
+			continue
+		}
+
+		if ids := a.Status.SilencedBy; len(ids) > 0 && o.SilenceService != nil {
+			key := strings.Join(ids, ",")
+			if _, seen := seenSilencedBy[key]; !seen {
+				seenSilencedBy[key] = struct{}{}
+				createdBy := make([]string, len(ids))
+				comments := make([]string, len(ids))
+				for i, id := range ids {
+					if sil, ok := s.silences[id]; ok {
+						createdBy[i] = deref(sil.CreatedBy)
+						comments[i] = commentPrefix(deref(sil.Comment))
+					}
+				}
+				sendInfo(ch, silencedByDesc, key, strings.Join(createdBy, ","), strings.Join(comments, "; "))
+			}
+		}
+
+		if fps := a.Status.InhibitedBy; len(fps) > 0 {
+			key := strings.Join(fps, ",")
+			if _, seen := seenInhibitedBy[key]; !seen {
+				seenInhibitedBy[key] = struct{}{}
+				names := make([]string, len(fps))
+				for i, fp := range fps {
+					names[i] = s.alertnames[fp]
+				}
+				sendInfo(ch, inhibitedByDesc, key, strings.Join(names, ","))
+			}
+		}
+	}
+}
+
+func sendInfo(ch chan<- prometheus.Metric, desc *prometheus.Desc, values ...string) {
+	m, err := prometheus.NewConstMetric(desc, prometheus.GaugeValue, 1, values...)
+	if err != nil {
+		log.Print("Error exporting alert suppressors", err)
+		return
+	}
+	ch <- m
+}
+
+// commentPrefix returns the first line of the given comment shortened to commentPrefixLength characters.
+func commentPrefix(c string) string {
+	c, _, _ = strings.Cut(strings.TrimSpace(c), "\n")
+	c = strings.TrimSpace(c)
+	if utf8.RuneCountInString(c) <= commentPrefixLength {
+		return c
+	}
+	return string([]rune(c)[:commentPrefixLength]) + "…"
+}
@@ -0,0 +1,96 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+	silencesmock "github.com/appuio/alerts_exporter/internal/silences_collector/mock"
+)
+
+func TestAlertsCollector_ResolveSuppressors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{
+					Alert:       models.Alert{Labels: map[string]string{"alertname": "Silenced"}},
+					Fingerprint: ptr("1"),
+					Status:      &models.AlertStatus{State: ptr("suppressed"), SilencedBy: []string{"s-1"}},
+				},
+				{
+					Alert:       models.Alert{Labels: map[string]string{"alertname": "AlsoSilenced"}},
+					Fingerprint: ptr("2"),
+					Status:      &models.AlertStatus{State: ptr("suppressed"), SilencedBy: []string{"s-1"}},
+				},
+				{
+					Alert:       models.Alert{Labels: map[string]string{"alertname": "Inhibited"}},
+					Fingerprint: ptr("3"),
+					Status:      &models.AlertStatus{State: ptr("suppressed"), InhibitedBy: []string{"4"}},
+				},
+			},
+		}, nil)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(p *alert.GetAlertsParams, _ ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+			require.Nil(t, p.Filter, "inhibiting alerts should be queried without filters")
+			return &alert.GetAlertsOK{
+				Payload: []*models.GettableAlert{
+					{
+						Alert:       models.Alert{Labels: map[string]string{"alertname": "ClusterDown"}},
+						Fingerprint: ptr("4"),
+					},
+				},
+			}, nil
+		})
+
+	mockSilenceService := silencesmock.NewMockClientService(ctrl)
+	mockSilenceService.
+		EXPECT().
+		GetSilences(gomock.Any(), gomock.Any()).
+		Return(&silence.GetSilencesOK{
+			Payload: models.GettableSilences{
+				{
+					ID: ptr("s-1"),
+					Silence: models.Silence{
+						CreatedBy: ptr("alice"),
+						Comment:   ptr("Planned maintenance\nTicket: OPS-1234"),
+					},
+				},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService:   mockAlertService,
+		SilenceService: mockSilenceService,
+		Filters:        []string{`alertname=~".+"`},
+
+		ResolveSuppressors: true,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alert_inhibited_by_info Names of the alerts inhibiting alerts queried from the Alertmanager API. Joinable to the alert series by the '_alerts_exporter_alert_inhibited_by' label.
+# TYPE alerts_exporter_alert_inhibited_by_info gauge
+alerts_exporter_alert_inhibited_by_info{_alerts_exporter_alert_inhibited_by="4",alertname="ClusterDown"} 1
+# HELP alerts_exporter_alert_silenced_by_info Creators and comments of the silences of alerts queried from the Alertmanager API. Joinable to the alert series by the '_alerts_exporter_alert_silenced_by' label.
+# TYPE alerts_exporter_alert_silenced_by_info gauge
+alerts_exporter_alert_silenced_by_info{_alerts_exporter_alert_silenced_by="s-1",comment="Planned maintenance",created_by="alice"} 1
+`),
+			"alerts_exporter_alert_inhibited_by_info", "alerts_exporter_alert_silenced_by_info",
+		),
+	)
+}
@@ -0,0 +1,63 @@
+package alertscollector
+
+import (
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectTimestamps sends the start, update and end timestamps of the given alerts sharing a series with the given labels.
+// The earliest start and the latest update and end of the alerts are sent. Unset timestamps are skipped.
+func (o *AlertsCollector) collectTimestamps(ch chan<- prometheus.Metric, as []*models.GettableAlert, keys, values []string) error {
+	var startsAt, updatedAt, endsAt time.Time
+	for _, a := range as {
+		startsAt = earliest(startsAt, a.StartsAt)
+		updatedAt = latest(updatedAt, a.UpdatedAt)
+		endsAt = latest(endsAt, a.EndsAt)
+	}
+
+	for _, ts := range []struct {
+		suffix, help string
+		t            time.Time
+	}{
+		{"starts_at_timestamp_seconds", "Unix timestamp at which alerts queried from the Alertmanager API started firing.", startsAt},
+		{"updated_at_timestamp_seconds", "Unix timestamp at which alerts queried from the Alertmanager API were last updated.", updatedAt},
+		{"ends_at_timestamp_seconds", "Unix timestamp at which alerts queried from the Alertmanager API are considered resolved if not updated.", endsAt},
+	} {
+		if ts.t.IsZero() {
+			continue
+		}
+		m, err := prometheus.NewConstMetric(
+			prometheus.NewDesc(o.companionMetricName(ts.suffix), ts.help, keys, o.ConstLabels),
+			prometheus.GaugeValue,
+			float64(ts.t.UnixNano())/1e9,
+			values...,
+		)
+		if err != nil {
+			return err
+		}
+		ch <- m
+	}
+	return nil
+}
+
+// earliest returns the earlier of the given times. Zero and unset times are ignored.
+func earliest(t time.Time, dt *strfmt.DateTime) time.Time {
+	if dt == nil || time.Time(*dt).IsZero() {
+		return t
+	}
+	if t.IsZero() || time.Time(*dt).Before(t) {
+		return time.Time(*dt)
+	}
+	return t
+}
+
+// latest returns the later of the given times. Zero and unset times are ignored.
+func latest(t time.Time, dt *strfmt.DateTime) time.Time {
+	if dt == nil || !time.Time(*dt).After(t) {
+		return t
+	}
+	return time.Time(*dt)
+}
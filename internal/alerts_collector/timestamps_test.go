@@ -0,0 +1,62 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+)
+
+func TestAlertsCollector_Timestamps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{
+					Alert: models.Alert{
+						Labels: map[string]string{
+							"alertname": "ImportantAlert",
+						},
+					},
+					StartsAt:  ptr(strfmt.DateTime(time.Unix(1700000000, 0))),
+					UpdatedAt: ptr(strfmt.DateTime(time.Unix(1700000300, 0))),
+					EndsAt:    ptr(strfmt.DateTime(time.Unix(1700000600, 500_000_000))),
+				},
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		WithTimestamps: true,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alert_starts_at_timestamp_seconds Unix timestamp at which alerts queried from the Alertmanager API started firing.
+# TYPE alerts_exporter_alert_starts_at_timestamp_seconds gauge
+alerts_exporter_alert_starts_at_timestamp_seconds{alertname="ImportantAlert"} 1.7e+09
+# HELP alerts_exporter_alert_updated_at_timestamp_seconds Unix timestamp at which alerts queried from the Alertmanager API were last updated.
+# TYPE alerts_exporter_alert_updated_at_timestamp_seconds gauge
+alerts_exporter_alert_updated_at_timestamp_seconds{alertname="ImportantAlert"} 1.7000003e+09
+# HELP alerts_exporter_alert_ends_at_timestamp_seconds Unix timestamp at which alerts queried from the Alertmanager API are considered resolved if not updated.
+# TYPE alerts_exporter_alert_ends_at_timestamp_seconds gauge
+alerts_exporter_alert_ends_at_timestamp_seconds{alertname="ImportantAlert"} 1.7000006005e+09
+`),
+			"alerts_exporter_alert_starts_at_timestamp_seconds", "alerts_exporter_alert_updated_at_timestamp_seconds", "alerts_exporter_alert_ends_at_timestamp_seconds",
+		),
+	)
+}
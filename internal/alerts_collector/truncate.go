@@ -0,0 +1,71 @@
+package alertscollector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultSeverityPriority is the order in which alerts are kept if AlertsCollector.SeverityPriority is empty.
+var DefaultSeverityPriority = []string{"critical", "error", "warning", "info"}
+
+// severityLabel is the alert label the severity priority is read from.
+const severityLabel = "severity"
+
+// sortByPriority returns the given alerts sorted by severity priority, then oldest first.
+// Ties are broken by fingerprint to keep the result stable between scrapes.
+// The given slice is not modified.
+func (o *AlertsCollector) sortByPriority(as []*models.GettableAlert) []*models.GettableAlert {
+	priority := o.SeverityPriority
+	if len(priority) == 0 {
+		priority = DefaultSeverityPriority
+	}
+	rank := func(a *models.GettableAlert) int {
+		if i := slices.Index(priority, a.Labels[severityLabel]); i >= 0 {
+			return i
+		}
+		return len(priority)
+	}
+	startsAt := func(a *models.GettableAlert) time.Time {
+		if a.StartsAt == nil {
+			return time.Time{}
+		}
+		return time.Time(*a.StartsAt)
+	}
+
+	sorted := slices.Clone(as)
+	slices.SortStableFunc(sorted, func(a, b *models.GettableAlert) int {
+		if ra, rb := rank(a), rank(b); ra != rb {
+			return ra - rb
+		}
+		if c := startsAt(a).Compare(startsAt(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(deref(a.Fingerprint), deref(b.Fingerprint))
+	})
+	return sorted
+}
+
+// truncate returns at most MaxSeries of the given series and the number of alerts of the dropped series.
+// The series must be built from alerts sorted by sortByPriority so the most important series come first.
+func (o *AlertsCollector) truncate(ss []*series) (_ []*series, dropped int) {
+	if o.MaxSeries <= 0 || len(ss) <= o.MaxSeries {
+		return ss, 0
+	}
+	for _, s := range ss[o.MaxSeries:] {
+		dropped += len(s.alerts)
+	}
+	return ss[:o.MaxSeries], dropped
+}
+
+// collectTruncated sends the number of alerts dropped because of the series limit.
+func (o *AlertsCollector) collectTruncated(ch chan<- prometheus.Metric, dropped int) {
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc(o.metricName()+"_truncated", "Number of alerts not exported because the maximum number of series was exceeded.", nil, o.ConstLabels),
+		prometheus.GaugeValue,
+		float64(dropped),
+	)
+}
@@ -0,0 +1,130 @@
+package alertscollector_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+	"github.com/appuio/alerts_exporter/internal/relabel"
+)
+
+func TestAlertsCollector_MaxSeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	newAlert := func(name, severity string, startsAt int64) *models.GettableAlert {
+		return &models.GettableAlert{
+			Alert: models.Alert{
+				Labels: map[string]string{
+					"alertname": name,
+					"severity":  severity,
+				},
+			},
+			Fingerprint: ptr(name),
+			StartsAt:    ptr(strfmt.DateTime(time.Unix(startsAt, 0))),
+		}
+	}
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				newAlert("NewWarning", "warning", 200),
+				newAlert("Unknown", "page", 0),
+				newAlert("OldWarning", "warning", 100),
+				newAlert("Critical", "critical", 300),
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		MaxSeries: 2,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{alertname="Critical",severity="critical"} 1
+alerts_exporter_alerts{alertname="OldWarning",severity="warning"} 1
+# HELP alerts_exporter_alerts_truncated Number of alerts not exported because the maximum number of series was exceeded.
+# TYPE alerts_exporter_alerts_truncated gauge
+alerts_exporter_alerts_truncated 2
+`),
+			"alerts_exporter_alerts", "alerts_exporter_alerts_truncated",
+		),
+	)
+}
+
+func TestAlertsCollector_MaxSeries_RelabelConfigs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	newAlert := func(name, pod, severity string) *models.GettableAlert {
+		return &models.GettableAlert{
+			Alert: models.Alert{
+				Labels: map[string]string{
+					"alertname": name,
+					"pod":       pod,
+					"severity":  severity,
+				},
+			},
+			Fingerprint: ptr(name + pod),
+		}
+	}
+
+	mockAlertService := mock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				newAlert("Watchdog", "", "critical"),
+				newAlert("PodCrashLooping", "app-0", "critical"),
+				newAlert("PodCrashLooping", "app-1", "critical"),
+				newAlert("PodNotReady", "app-0", "warning"),
+				newAlert("Info", "", "info"),
+			},
+		}, nil)
+
+	subject := &alertscollector.AlertsCollector{
+		AlertService: mockAlertService,
+
+		MaxSeries: 2,
+		RelabelConfigs: []*relabel.Config{
+			{
+				SourceLabels: []string{"alertname"},
+				Regex:        relabel.MustNewRegex("Watchdog"),
+				Action:       relabel.Drop,
+			},
+			{
+				Regex:  relabel.MustNewRegex("pod"),
+				Action: relabel.LabelDrop,
+			},
+		},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{alertname="PodCrashLooping",severity="critical"} 2
+alerts_exporter_alerts{alertname="PodNotReady",severity="warning"} 1
+# HELP alerts_exporter_alerts_truncated Number of alerts not exported because the maximum number of series was exceeded.
+# TYPE alerts_exporter_alerts_truncated gauge
+alerts_exporter_alerts_truncated 1
+`),
+			"alerts_exporter_alerts", "alerts_exporter_alerts_truncated",
+		),
+	)
+}
@@ -0,0 +1,181 @@
+package amclient
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-openapi/runtime"
+	openapiclient "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	alertmanagerclient "github.com/prometheus/alertmanager/api/v2/client"
+
+	"github.com/appuio/alerts_exporter/internal/config"
+	"github.com/appuio/alerts_exporter/internal/fileauth"
+	"github.com/appuio/alerts_exporter/internal/oauth2auth"
+	"github.com/appuio/alerts_exporter/internal/saauth"
+)
+
+// New creates a new Alertmanager API client for the given host.
+// The returned stop function closes idle connections, releases resources held by the client's authentication and must be called once the client is no longer used.
+func New(host string, c config.ClientConfig) (*alertmanagerclient.AlertmanagerAPI, func(), error) {
+	t, err := NewTransport(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.Client(host), t.Stop, nil
+}
+
+// NewRuntime creates an OpenAPI runtime for the API at the given host.
+// The base path of the client config takes precedence over the given default base path.
+// TLS, static headers and authentication are configured from the given client config.
+// The returned stop function closes idle connections, releases resources held by the runtime's authentication and must be called once the runtime is no longer used.
+func NewRuntime(host, defaultBasePath string, c config.ClientConfig) (*openapiclient.Runtime, func(), error) {
+	t, err := NewTransport(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t.Runtime(host, defaultBasePath), t.Stop, nil
+}
+
+// Transport holds the HTTP client and authentication configured by a client config.
+// It can be shared by the clients of several hosts, so credential files are reloaded and tokens are requested once for all of them.
+type Transport struct {
+	client   *http.Client
+	schemes  []string
+	basePath string
+	auth     runtime.ClientAuthInfoWriter
+	stops    []func()
+}
+
+// NewTransport creates a new Transport configuring TLS, static headers and authentication from the given client config.
+// Stop must be called once the transport is no longer used.
+func NewTransport(c config.ClientConfig) (*Transport, error) {
+	opts := openapiclient.TLSClientOptions{
+		Certificate: c.TLS.Cert,
+		Key:         c.TLS.Key,
+		CA:          c.TLS.CA,
+		ServerName:  c.TLS.ServerName,
+	}
+	if c.TLS.Insecure {
+		opts.InsecureSkipVerify = true
+		opts.ServerName = ""
+	}
+
+	hc, err := openapiclient.TLSClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TLS client: %w", err)
+	}
+
+	t := &Transport{
+		client:   hc,
+		basePath: c.BasePath,
+		// Every transport has its own connection pool, its keep-alive connections must be closed once it is no longer used.
+		stops: []func(){hc.CloseIdleConnections},
+	}
+	if c.TLS.Enabled {
+		t.schemes = []string{"https"}
+	}
+
+	// Writers are applied in order, authentication headers take precedence over static headers.
+	var auths []runtime.ClientAuthInfoWriter
+	newFile := func(path string) (*fileauth.File, error) {
+		f, err := fileauth.NewFile(path, 0)
+		if err != nil {
+			t.Stop()
+			return nil, err
+		}
+		t.stops = append(t.stops, f.Stop)
+		return f, nil
+	}
+
+	if len(c.Headers) > 0 {
+		auths = append(auths, headers(c.Headers))
+	}
+	for name, path := range c.HeaderFiles {
+		f, err := newFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("header %q: %w", name, err)
+		}
+		auths = append(auths, fileauth.Header(name, f))
+	}
+	if c.BearerToken != "" {
+		auths = append(auths, openapiclient.BearerToken(c.BearerToken))
+	}
+	if c.BearerTokenFile != "" {
+		f, err := newFile(c.BearerTokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("bearer token: %w", err)
+		}
+		auths = append(auths, fileauth.BearerToken(f))
+	}
+	if c.BasicAuth != nil {
+		f, err := newFile(c.BasicAuth.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("basic auth password: %w", err)
+		}
+		auths = append(auths, fileauth.BasicAuth(c.BasicAuth.Username, f))
+	}
+	if c.OAuth2 != nil {
+		oa, err := oauth2auth.NewClientCredentialsAuthInfoWriter(c.OAuth2.TokenURL, c.OAuth2.ClientID, c.OAuth2.ClientSecretFile, c.OAuth2.Scopes, nil)
+		if err != nil {
+			t.Stop()
+			return nil, fmt.Errorf("oauth2: %w", err)
+		}
+		t.stops = append(t.stops, oa.Stop)
+		auths = append(auths, oa)
+		hc.Transport = oa.Transport(hc.Transport)
+	}
+	if c.K8sBearerTokenAuth {
+		sa, err := saauth.NewServiceAccountAuthInfoWriter("", 0)
+		if err != nil {
+			t.Stop()
+			return nil, err
+		}
+		t.stops = append(t.stops, sa.Stop)
+		auths = append(auths, sa)
+	}
+	if len(auths) > 0 {
+		t.auth = openapiclient.Compose(auths...)
+	}
+
+	return t, nil
+}
+
+// Runtime creates an OpenAPI runtime for the API at the given host using the transport.
+// The base path of the client config takes precedence over the given default base path.
+func (t *Transport) Runtime(host, defaultBasePath string) *openapiclient.Runtime {
+	basePath := defaultBasePath
+	if t.basePath != "" {
+		basePath = t.basePath
+	}
+	rt := openapiclient.NewWithClient(host, basePath, t.schemes, t.client)
+	if t.auth != nil {
+		rt.DefaultAuthentication = t.auth
+	}
+	return rt
+}
+
+// Client creates an Alertmanager API client for the given host using the transport.
+func (t *Transport) Client(host string) *alertmanagerclient.AlertmanagerAPI {
+	return alertmanagerclient.New(t.Runtime(host, alertmanagerclient.DefaultBasePath), nil)
+}
+
+// Stop closes idle connections and releases resources held by the transport's authentication.
+// Clients using the transport must not be used afterwards.
+func (t *Transport) Stop() {
+	for _, s := range t.stops {
+		s()
+	}
+}
+
+// headers returns an auth info writer setting the given static headers on every request.
+func headers(h map[string]string) runtime.ClientAuthInfoWriter {
+	return runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+		for k, v := range h {
+			if err := r.SetHeaderParam(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,168 @@
+package amclient_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appuio/alerts_exporter/internal/amclient"
+	"github.com/appuio/alerts_exporter/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/alerts" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"labels":{"alertname":"Test"}}]`))
+	}))
+	defer srv.Close()
+
+	ac, stop, err := amclient.New(strings.TrimPrefix(srv.URL, "http://"), config.ClientConfig{
+		BearerToken: "token",
+	})
+	require.NoError(t, err)
+	defer stop()
+
+	res, err := ac.Alert.GetAlerts(alert.NewGetAlertsParams())
+	require.NoError(t, err)
+	require.Len(t, res.Payload, 1)
+	require.Equal(t, "Test", res.Payload[0].Labels["alertname"])
+}
+
+func TestNew_BasePathAndHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/alertmanager/api/v2/alerts" || r.Header.Get("X-Scope-OrgID") != "tenant-a" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"labels":{"alertname":"Test"}}]`))
+	}))
+	defer srv.Close()
+
+	target := config.Target{
+		Host: strings.TrimPrefix(srv.URL, "http://"),
+		ClientConfig: config.ClientConfig{
+			BasePath:    "/alertmanager/api/v2",
+			Headers:     map[string]string{"Authorization": "Basic overridden"},
+			BearerToken: "token",
+		},
+	}.ForTenant("tenant-a")
+	ac, stop, err := amclient.New(target.Host, target.ClientConfig)
+	require.NoError(t, err)
+	defer stop()
+
+	res, err := ac.Alert.GetAlerts(alert.NewGetAlertsParams())
+	require.NoError(t, err)
+	require.Len(t, res.Payload, 1)
+}
+
+func TestNew_CredentialFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/password", []byte("secret\n"), 0600))
+	require.NoError(t, os.WriteFile(dir+"/api-key", []byte("key"), 0600))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "exporter" || password != "secret" || r.Header.Get("X-Api-Key") != "key" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	ac, stop, err := amclient.New(strings.TrimPrefix(srv.URL, "http://"), config.ClientConfig{
+		HeaderFiles: map[string]string{"X-Api-Key": dir + "/api-key"},
+		BasicAuth:   &config.BasicAuth{Username: "exporter", PasswordFile: dir + "/password"},
+	})
+	require.NoError(t, err)
+	defer stop()
+
+	_, err = ac.Alert.GetAlerts(alert.NewGetAlertsParams())
+	require.NoError(t, err)
+}
+
+func TestNew_CredentialFileMissing(t *testing.T) {
+	_, _, err := amclient.New("localhost:9093", config.ClientConfig{
+		BearerTokenFile: t.TempDir() + "/missing",
+	})
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestNew_OAuth2(t *testing.T) {
+	secretFile := t.TempDir() + "/secret"
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret"), 0600))
+
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "exporter" || secret != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer tokenSrv.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	ac, stop, err := amclient.New(strings.TrimPrefix(srv.URL, "http://"), config.ClientConfig{
+		OAuth2: &config.OAuth2{
+			TokenURL:         tokenSrv.URL,
+			ClientID:         "exporter",
+			ClientSecretFile: secretFile,
+		},
+	})
+	require.NoError(t, err)
+	defer stop()
+
+	_, err = ac.Alert.GetAlerts(alert.NewGetAlertsParams())
+	require.NoError(t, err)
+}
+
+func TestNew_StopClosesIdleConnections(t *testing.T) {
+	var open atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, s http.ConnState) {
+		switch s {
+		case http.StateNew:
+			open.Add(1)
+		case http.StateClosed, http.StateHijacked:
+			open.Add(-1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	ac, stop, err := amclient.New(strings.TrimPrefix(srv.URL, "http://"), config.ClientConfig{})
+	require.NoError(t, err)
+
+	_, err = ac.Alert.GetAlerts(alert.NewGetAlertsParams())
+	require.NoError(t, err)
+	require.EqualValues(t, 1, open.Load(), "connection should be kept alive")
+
+	stop()
+	require.Eventually(t, func() bool { return open.Load() == 0 }, 5*time.Second, time.Millisecond)
+}
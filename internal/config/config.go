@@ -0,0 +1,382 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/haalerts"
+	"github.com/appuio/alerts_exporter/internal/relabel"
+)
+
+// Config is the configuration file of the exporter.
+type Config struct {
+	// Targets is a list of Alertmanagers to query.
+	// Series exported for a target are labeled with the target's name.
+	Targets []Target `yaml:"targets"`
+	// Modules are named connection settings used by the /probe endpoint.
+	// The 'default' module is used if the probe request does not name a module. It connects without TLS and credentials unless configured.
+	Modules map[string]ClientConfig `yaml:"modules"`
+	// AllowedOverrides lists the URL query parameters allowed to override collector settings per scrape.
+	// Overriding is not permitted if empty.
+	AllowedOverrides []string `yaml:"allowed_overrides"`
+	// Queries are named alert queries exported as separate metrics.
+	// If empty, a single query is configured from the command line flags.
+	Queries []Query `yaml:"queries"`
+	// RelabelConfigs are Prometheus-style relabel configs applied to the labels of every exported alert.
+	RelabelConfigs []*relabel.Config `yaml:"relabel_configs"`
+}
+
+// Query is a named alert query exported as its own metric.
+type Query struct {
+	// Name identifies the query.
+	Name string `yaml:"name"`
+	// MetricName is the name of the exported metric. Defaults to 'alerts_exporter_<name>_alerts'.
+	MetricName string `yaml:"metric_name"`
+	// Filters is a list of Alertmanager matchers to filter alerts by. Multiple matchers are ANDed.
+	Filters []string `yaml:"filters"`
+	// Receiver is a regex matching the receivers of the alerts to query. Alerts of all receivers are queried if empty.
+	Receiver string `yaml:"receiver"`
+
+	// Active, Silenced, Inhibited and Unprocessed select the alert states to query. Unset states are queried.
+	Active      *bool `yaml:"active"`
+	Silenced    *bool `yaml:"silenced"`
+	Inhibited   *bool `yaml:"inhibited"`
+	Unprocessed *bool `yaml:"unprocessed"`
+
+	// AggregateBy exports the number of alerts grouped by these labels and the alert state instead of one series per alert.
+	AggregateBy []string `yaml:"aggregate_by"`
+}
+
+// Metric returns the name of the metric exported for the query.
+func (q Query) Metric() string {
+	if q.MetricName != "" {
+		return q.MetricName
+	}
+	return "alerts_exporter_" + q.Name + "_alerts"
+}
+
+// Target is an Alertmanager to query.
+type Target struct {
+	// Name identifies the target and is added as a label to exported series.
+	// Only letters, digits, '_', '.' and '-' are allowed as the name is used in handler paths.
+	Name string `yaml:"name"`
+	// Host is the host of the Alertmanager.
+	Host string `yaml:"host"`
+	// Peers are the hosts of the members of an Alertmanager cluster.
+	// If set, all peers are queried and their alerts are deduplicated. Mutually exclusive with Host.
+	Peers []string `yaml:"peers"`
+	// HAStatusPolicy decides which status wins if peers disagree about an alert.
+	// One of "most-suppressed" (default), "least-suppressed" or "first-peer".
+	HAStatusPolicy string `yaml:"ha_status_policy"`
+	// Webhook receives the alerts from Alertmanager webhook notifications on '/webhook/<name>' instead of querying an Alertmanager.
+	// Mutually exclusive with Host and Peers.
+	// If bearer_token, bearer_token_file or basic_auth is set, notifications must carry these credentials.
+	Webhook bool `yaml:"webhook"`
+	// PrometheusHost is the host of a Prometheus or Thanos Ruler whose firing and pending alerts are queried from '/api/v1/alerts' instead of an Alertmanager.
+	// Mutually exclusive with Host, Peers and Webhook.
+	PrometheusHost string `yaml:"prometheus_host"`
+	// Tenants of a multi-tenant Alertmanager such as Grafana Mimir or Cortex.
+	// If set, each tenant is queried separately with the TenantHeader set and its series are labeled with 'tenant'.
+	Tenants []string `yaml:"tenants"`
+
+	ClientConfig `yaml:",inline"`
+}
+
+// TenantHeader is the header selecting the tenant of multi-tenant Alertmanagers.
+const TenantHeader = "X-Scope-OrgID"
+
+// ForTenant returns a copy of the target querying the given tenant.
+// The TenantHeader is set to the tenant and Tenants is cleared.
+func (t Target) ForTenant(tenant string) Target {
+	h := make(map[string]string, len(t.Headers)+1)
+	for k, v := range t.Headers {
+		h[k] = v
+	}
+	h[TenantHeader] = tenant
+	t.Headers = h
+	t.Tenants = nil
+	return t
+}
+
+// ClientConfig configures how to connect to an Alertmanager.
+type ClientConfig struct {
+	TLS TLSConfig `yaml:"tls"`
+
+	// BasePath is the path prefix of the API, such as '/alertmanager/api/v2' for Grafana Mimir.
+	// Defaults to '/api/v2' for Alertmanagers and '/api/v1' for Prometheus and Thanos Ruler.
+	BasePath string `yaml:"base_path"`
+	// Headers are static headers added to every request.
+	Headers map[string]string `yaml:"headers"`
+
+	// HeaderFiles are headers added to every request whose values are read from files and reloaded periodically.
+	// Keys are header names, values are file paths.
+	HeaderFiles map[string]string `yaml:"header_files"`
+
+	// BearerToken is a static bearer token to use for authentication.
+	BearerToken string `yaml:"bearer_token"`
+	// BearerTokenFile is the path to a file containing the bearer token to use for authentication.
+	// The file is reloaded periodically.
+	BearerTokenFile string `yaml:"bearer_token_file"`
+	// BasicAuth enables basic authentication.
+	BasicAuth *BasicAuth `yaml:"basic_auth"`
+	// K8sBearerTokenAuth enables Kubernetes service account bearer token authentication.
+	K8sBearerTokenAuth bool `yaml:"k8s_bearer_token_auth"`
+	// OAuth2 enables OAuth2 client credentials authentication.
+	OAuth2 *OAuth2 `yaml:"oauth2"`
+}
+
+// OAuth2 configures OAuth2 client credentials authentication.
+type OAuth2 struct {
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string `yaml:"token_url"`
+	ClientID string `yaml:"client_id"`
+	// ClientSecretFile is the path to a file containing the client secret. The file is reloaded periodically.
+	ClientSecretFile string `yaml:"client_secret_file"`
+	// Scopes are the scopes requested for the token.
+	Scopes []string `yaml:"scopes"`
+}
+
+// BasicAuth configures basic authentication.
+type BasicAuth struct {
+	Username string `yaml:"username"`
+	// PasswordFile is the path to a file containing the password. The file is reloaded periodically.
+	PasswordFile string `yaml:"password_file"`
+}
+
+// TLSConfig configures TLS when connecting to an Alertmanager.
+type TLSConfig struct {
+	// Enabled enables TLS.
+	Enabled bool `yaml:"enabled"`
+	// Cert is the path to the client certificate.
+	Cert string `yaml:"cert"`
+	// Key is the path to the client certificate key.
+	Key string `yaml:"key"`
+	// CA is the path to the CA certificate. System certificates are used if empty.
+	CA string `yaml:"ca"`
+	// ServerName is used to verify the hostname on the returned certificates.
+	ServerName string `yaml:"server_name"`
+	// Insecure disables TLS host verification.
+	Insecure bool `yaml:"insecure"`
+}
+
+// LoadFile reads and validates the configuration from the given file.
+func LoadFile(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Load reads and validates the configuration from the given reader.
+// Unknown fields are rejected.
+func Load(r io.Reader) (Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, fmt.Errorf("failed to decode config: %w", err)
+	}
+	return c, c.Validate()
+}
+
+// Validate checks the configuration for errors.
+func (c Config) Validate() error {
+	for _, o := range c.AllowedOverrides {
+		if !slices.Contains(alertscollector.OverrideNames, o) {
+			return fmt.Errorf("allowed_overrides: unknown override %q", o)
+		}
+	}
+
+	for i, rc := range c.RelabelConfigs {
+		if rc == nil {
+			return fmt.Errorf("relabel_configs %d: empty relabel config", i)
+		}
+		if err := rc.Validate(); err != nil {
+			return fmt.Errorf("relabel_configs %d: %w", i, err)
+		}
+	}
+
+	names := make(map[string]struct{}, len(c.Targets))
+	for i, t := range c.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("target %d: name is required", i)
+		}
+		if !isValidPathSegment(t.Name, validTargetName) {
+			return fmt.Errorf("target %d: invalid name %q, only letters, digits, '_', '.' and '-' are allowed", i, t.Name)
+		}
+		sources := 0
+		for _, set := range []bool{t.Host != "", len(t.Peers) > 0, t.Webhook, t.PrometheusHost != ""} {
+			if set {
+				sources++
+			}
+		}
+		if sources == 0 {
+			return fmt.Errorf("target %q: host, peers, webhook or prometheus_host is required", t.Name)
+		}
+		if sources > 1 {
+			return fmt.Errorf("target %q: host, peers, webhook and prometheus_host are mutually exclusive", t.Name)
+		}
+		if _, err := haalerts.ParseStatusPolicy(t.HAStatusPolicy); err != nil {
+			return fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		if err := t.ClientConfig.Validate(); err != nil {
+			return fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		if err := ValidateTenants(t.Tenants); err != nil {
+			return fmt.Errorf("target %q: %w", t.Name, err)
+		}
+		if t.Webhook && len(t.Tenants) > 0 {
+			return fmt.Errorf("target %q: tenants are not supported for webhook targets", t.Name)
+		}
+		if t.Webhook && (t.OAuth2 != nil || t.K8sBearerTokenAuth) {
+			return fmt.Errorf("target %q: only bearer_token, bearer_token_file and basic_auth are supported for webhook targets", t.Name)
+		}
+		if _, ok := names[t.Name]; ok {
+			return fmt.Errorf("target %q: duplicate name", t.Name)
+		}
+		names[t.Name] = struct{}{}
+	}
+
+	for name, m := range c.Modules {
+		if err := m.Validate(); err != nil {
+			return fmt.Errorf("module %q: %w", name, err)
+		}
+	}
+
+	queryNames := make(map[string]struct{}, len(c.Queries))
+	metricNames := make(map[string]struct{}, len(c.Queries))
+	for i, q := range c.Queries {
+		if q.Name == "" {
+			return fmt.Errorf("query %d: name is required", i)
+		}
+		if _, ok := queryNames[q.Name]; ok {
+			return fmt.Errorf("query %q: duplicate name", q.Name)
+		}
+		queryNames[q.Name] = struct{}{}
+		if !model.IsValidLegacyMetricName(q.Metric()) {
+			return fmt.Errorf("query %q: invalid metric name %q", q.Name, q.Metric())
+		}
+		if _, ok := metricNames[q.Metric()]; ok {
+			return fmt.Errorf("query %q: duplicate metric name %q", q.Name, q.Metric())
+		}
+		metricNames[q.Metric()] = struct{}{}
+		if _, err := regexp.Compile(q.Receiver); err != nil {
+			return fmt.Errorf("query %q: invalid receiver regex %q: %w", q.Name, q.Receiver, err)
+		}
+		if err := alertscollector.ValidateAggregateBy(q.AggregateBy); err != nil {
+			return fmt.Errorf("query %q: %w", q.Name, err)
+		}
+	}
+	if err := ValidateReceivers(c.Targets, c.Queries); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate checks the client config for errors.
+func (c ClientConfig) Validate() error {
+	if c.BasePath != "" && !strings.HasPrefix(c.BasePath, "/") {
+		return fmt.Errorf("base_path %q must start with '/'", c.BasePath)
+	}
+	for k := range c.Headers {
+		if !validHeaderName.MatchString(k) {
+			return fmt.Errorf("headers: invalid header name %q", k)
+		}
+	}
+	for k, path := range c.HeaderFiles {
+		if !validHeaderName.MatchString(k) {
+			return fmt.Errorf("header_files: invalid header name %q", k)
+		}
+		if path == "" {
+			return fmt.Errorf("header_files: empty path for header %q", k)
+		}
+	}
+	if c.BasicAuth != nil && (c.BasicAuth.Username == "" || c.BasicAuth.PasswordFile == "") {
+		return errors.New("basic_auth: username and password_file are required")
+	}
+	if c.OAuth2 != nil {
+		if c.OAuth2.TokenURL == "" || c.OAuth2.ClientID == "" || c.OAuth2.ClientSecretFile == "" {
+			return errors.New("oauth2: token_url, client_id and client_secret_file are required")
+		}
+		if u, err := url.Parse(c.OAuth2.TokenURL); err != nil || !u.IsAbs() {
+			return fmt.Errorf("oauth2: invalid token_url %q", c.OAuth2.TokenURL)
+		}
+	}
+	if (c.BearerTokenFile != "" || c.BasicAuth != nil || c.OAuth2 != nil) && c.authMethods() > 1 {
+		return errors.New("bearer_token, bearer_token_file, basic_auth, oauth2 and k8s_bearer_token_auth are mutually exclusive")
+	}
+	return nil
+}
+
+// authMethods returns the number of configured authentication methods.
+func (c ClientConfig) authMethods() int {
+	n := 0
+	for _, set := range []bool{c.BearerToken != "", c.BearerTokenFile != "", c.BasicAuth != nil, c.OAuth2 != nil, c.K8sBearerTokenAuth} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// validHeaderName matches the token characters allowed in HTTP header names.
+var validHeaderName = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// ValidateReceivers checks that no query selects alerts by receiver if any target queries a Prometheus.
+// Prometheus alert sources do not know receivers and fail every query with a receiver.
+func ValidateReceivers(targets []Target, queries []Query) error {
+	for _, t := range targets {
+		if t.PrometheusHost == "" {
+			continue
+		}
+		for _, q := range queries {
+			if q.Receiver != "" {
+				return fmt.Errorf("target %q: query %q: receiver is not supported for prometheus_host targets", t.Name, q.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// validTargetName matches the characters allowed in target names.
+// Target names are used in the paths of the health check and webhook handlers.
+var validTargetName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// validTenant matches the characters allowed in tenant IDs by Grafana Mimir and Cortex.
+// Tenants are used in the paths of the health check handlers.
+var validTenant = regexp.MustCompile(`^[A-Za-z0-9!_.*'()-]+$`)
+
+// isValidPathSegment returns whether s matches re and is usable as a single URL path segment.
+func isValidPathSegment(s string, re *regexp.Regexp) bool {
+	return s != "." && s != ".." && re.MatchString(s)
+}
+
+// ValidateTenants checks that the given tenants are non-empty, unique and only contain characters allowed in tenant IDs.
+func ValidateTenants(tenants []string) error {
+	seen := make(map[string]struct{}, len(tenants))
+	for _, t := range tenants {
+		if t == "" {
+			return errors.New("tenants: empty tenant")
+		}
+		if !isValidPathSegment(t, validTenant) {
+			return fmt.Errorf("tenants: invalid tenant %q, only letters, digits and the characters !-_.*'() are allowed", t)
+		}
+		if _, ok := seen[t]; ok {
+			return fmt.Errorf("tenants: duplicate tenant %q", t)
+		}
+		seen[t] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/appuio/alerts_exporter/internal/config"
+)
+
+func TestLoad(t *testing.T) {
+	c, err := config.Load(strings.NewReader(`
+targets:
+- name: cluster-a
+  host: alertmanager.cluster-a:9093
+  tls:
+    enabled: true
+    ca: /etc/ca.crt
+  k8s_bearer_token_auth: true
+- name: tenant-b
+  host: alertmanager.tenant-b:9093
+  bearer_token: secret
+- name: ha
+  peers:
+  - alertmanager-0:9093
+  - alertmanager-1:9093
+  ha_status_policy: first-peer
+- name: managed
+  webhook: true
+- name: mimir
+  host: mimir-alertmanager:8080
+  base_path: /alertmanager/api/v2
+  headers:
+    X-Custom: value
+  tenants: [team-a, team-b]
+- name: secured
+  host: alertmanager.secured:9093
+  header_files:
+    X-Api-Key: /etc/secret/api-key
+  basic_auth:
+    username: exporter
+    password_file: /etc/secret/password
+modules:
+  token:
+    bearer_token_file: /etc/secret/token
+  oauth2:
+    oauth2:
+      token_url: https://sso.example.com/token
+      client_id: exporter
+      client_secret_file: /etc/secret/client-secret
+      scopes: [alerts]
+  tls:
+    tls:
+      enabled: true
+allowed_overrides:
+- filter
+- silenced
+queries:
+- name: slo
+  filters:
+  - slo="true"
+- name: paging
+  metric_name: alerts_exporter_paging_alerts_total
+  filters:
+  - severity="critical"
+  silenced: false
+  inhibited: false
+- name: namespace
+  aggregate_by: [namespace, severity]
+- name: team_a
+  receiver: team-a-.*
+`))
+	require.NoError(t, err)
+	require.Equal(t, config.Config{
+		Targets: []config.Target{
+			{
+				Name: "cluster-a",
+				Host: "alertmanager.cluster-a:9093",
+				ClientConfig: config.ClientConfig{
+					TLS: config.TLSConfig{
+						Enabled: true,
+						CA:      "/etc/ca.crt",
+					},
+					K8sBearerTokenAuth: true,
+				},
+			},
+			{
+				Name: "tenant-b",
+				Host: "alertmanager.tenant-b:9093",
+				ClientConfig: config.ClientConfig{
+					BearerToken: "secret",
+				},
+			},
+			{
+				Name:           "ha",
+				Peers:          []string{"alertmanager-0:9093", "alertmanager-1:9093"},
+				HAStatusPolicy: "first-peer",
+			},
+			{
+				Name:    "managed",
+				Webhook: true,
+			},
+			{
+				Name:    "mimir",
+				Host:    "mimir-alertmanager:8080",
+				Tenants: []string{"team-a", "team-b"},
+				ClientConfig: config.ClientConfig{
+					BasePath: "/alertmanager/api/v2",
+					Headers:  map[string]string{"X-Custom": "value"},
+				},
+			},
+			{
+				Name: "secured",
+				Host: "alertmanager.secured:9093",
+				ClientConfig: config.ClientConfig{
+					HeaderFiles: map[string]string{"X-Api-Key": "/etc/secret/api-key"},
+					BasicAuth: &config.BasicAuth{
+						Username:     "exporter",
+						PasswordFile: "/etc/secret/password",
+					},
+				},
+			},
+		},
+		Modules: map[string]config.ClientConfig{
+			"token": {
+				BearerTokenFile: "/etc/secret/token",
+			},
+			"oauth2": {
+				OAuth2: &config.OAuth2{
+					TokenURL:         "https://sso.example.com/token",
+					ClientID:         "exporter",
+					ClientSecretFile: "/etc/secret/client-secret",
+					Scopes:           []string{"alerts"},
+				},
+			},
+			"tls": {
+				TLS: config.TLSConfig{Enabled: true},
+			},
+		},
+		AllowedOverrides: []string{"filter", "silenced"},
+		Queries: []config.Query{
+			{
+				Name:    "slo",
+				Filters: []string{`slo="true"`},
+			},
+			{
+				Name:       "paging",
+				MetricName: "alerts_exporter_paging_alerts_total",
+				Filters:    []string{`severity="critical"`},
+				Silenced:   ptr(false),
+				Inhibited:  ptr(false),
+			},
+			{
+				Name:        "namespace",
+				AggregateBy: []string{"namespace", "severity"},
+			},
+			{
+				Name:     "team_a",
+				Receiver: "team-a-.*",
+			},
+		},
+	}, c)
+	require.Equal(t, "alerts_exporter_slo_alerts", c.Queries[0].Metric())
+	require.Equal(t, "alerts_exporter_paging_alerts_total", c.Queries[1].Metric())
+}
+
+func TestLoad_PrometheusHost(t *testing.T) {
+	c, err := config.Load(strings.NewReader(`
+targets:
+- name: rules
+  prometheus_host: thanos-ruler:10902
+queries:
+- name: firing
+  active: true
+`))
+	require.NoError(t, err)
+	require.Equal(t, []config.Target{{Name: "rules", PrometheusHost: "thanos-ruler:10902"}}, c.Targets)
+}
+
+func TestLoad_Empty(t *testing.T) {
+	c, err := config.Load(strings.NewReader(""))
+	require.NoError(t, err)
+	require.Empty(t, c.Targets)
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config string
+		err    string
+	}{
+		"unknown field": {
+			config: "targetz: []",
+			err:    "field targetz not found",
+		},
+		"missing name": {
+			config: "targets: [{host: localhost:9093}]",
+			err:    "name is required",
+		},
+		"missing host": {
+			config: "targets: [{name: a}]",
+			err:    "host, peers, webhook or prometheus_host is required",
+		},
+		"host and peers": {
+			config: "targets: [{name: a, host: a:9093, peers: [a-0:9093]}]",
+			err:    "mutually exclusive",
+		},
+		"host and webhook": {
+			config: "targets: [{name: a, host: a:9093, webhook: true}]",
+			err:    "mutually exclusive",
+		},
+		"webhook and prometheus host": {
+			config: "targets: [{name: a, webhook: true, prometheus_host: prometheus:9090}]",
+			err:    "mutually exclusive",
+		},
+		"relative base path": {
+			config: "targets: [{name: a, host: a:9093, base_path: api/v2}]",
+			err:    "must start with '/'",
+		},
+		"invalid header name": {
+			config: "targets: [{name: a, host: a:9093, headers: {'X Custom': value}}]",
+			err:    "invalid header name",
+		},
+		"basic auth without password file": {
+			config: "targets: [{name: a, host: a:9093, basic_auth: {username: a}}]",
+			err:    "username and password_file are required",
+		},
+		"bearer token file and basic auth": {
+			config: "targets: [{name: a, host: a:9093, bearer_token_file: /token, basic_auth: {username: a, password_file: /password}}]",
+			err:    "mutually exclusive",
+		},
+		"oauth2 without client secret file": {
+			config: "targets: [{name: a, host: a:9093, oauth2: {token_url: 'https://sso/token', client_id: a}}]",
+			err:    "token_url, client_id and client_secret_file are required",
+		},
+		"oauth2 relative token url": {
+			config: "targets: [{name: a, host: a:9093, oauth2: {token_url: /token, client_id: a, client_secret_file: /secret}}]",
+			err:    "invalid token_url",
+		},
+		"oauth2 and bearer token": {
+			config: "targets: [{name: a, host: a:9093, bearer_token: a, oauth2: {token_url: 'https://sso/token', client_id: a, client_secret_file: /secret}}]",
+			err:    "mutually exclusive",
+		},
+		"invalid header file name": {
+			config: "targets: [{name: a, host: a:9093, header_files: {'X Custom': /value}}]",
+			err:    "invalid header name",
+		},
+		"invalid name": {
+			config: "targets: [{name: 'cluster {a}', host: a:9093}]",
+			err:    `invalid name "cluster {a}"`,
+		},
+		"dot name": {
+			config: "targets: [{name: '..', host: a:9093}]",
+			err:    `invalid name ".."`,
+		},
+		"invalid tenant": {
+			config: "targets: [{name: a, host: a:9093, tenants: ['team a']}]",
+			err:    `invalid tenant "team a"`,
+		},
+		"duplicate tenant": {
+			config: "targets: [{name: a, host: a:9093, tenants: [a, a]}]",
+			err:    "duplicate tenant",
+		},
+		"webhook tenants": {
+			config: "targets: [{name: a, webhook: true, tenants: [a]}]",
+			err:    "not supported for webhook targets",
+		},
+		"webhook oauth2": {
+			config: "targets: [{name: a, webhook: true, oauth2: {token_url: 'https://auth/token', client_id: a, client_secret_file: /secret}}]",
+			err:    "only bearer_token, bearer_token_file and basic_auth are supported for webhook targets",
+		},
+		"invalid module": {
+			config: "modules: {a: {base_path: api}}",
+			err:    `module "a"`,
+		},
+		"unknown status policy": {
+			config: "targets: [{name: a, peers: [a-0:9093], ha_status_policy: random}]",
+			err:    "unknown status policy",
+		},
+		"unknown override": {
+			config: "allowed_overrides: [metric_name]",
+			err:    `unknown override "metric_name"`,
+		},
+		"missing query name": {
+			config: "queries: [{filters: []}]",
+			err:    "name is required",
+		},
+		"duplicate query name": {
+			config: "queries: [{name: a}, {name: a, metric_name: b}]",
+			err:    "duplicate name",
+		},
+		"invalid metric name": {
+			config: "queries: [{name: a, metric_name: a-b}]",
+			err:    `invalid metric name "a-b"`,
+		},
+		"duplicate metric name": {
+			config: "queries: [{name: a}, {name: b, metric_name: alerts_exporter_a_alerts}]",
+			err:    "duplicate metric name",
+		},
+		"invalid receiver regex": {
+			config: "queries: [{name: a, receiver: 'team-('}]",
+			err:    `invalid receiver regex "team-("`,
+		},
+		"receiver with prometheus host": {
+			config: "{targets: [{name: a, prometheus_host: prometheus:9090}], queries: [{name: a, receiver: team-a}]}",
+			err:    "receiver is not supported for prometheus_host targets",
+		},
+		"invalid aggregation label": {
+			config: "queries: [{name: a, aggregate_by: [kubernetes.io/name]}]",
+			err:    `invalid aggregation label "kubernetes.io/name"`,
+		},
+		"unknown relabel action": {
+			config: "relabel_configs: [{action: keepall}]",
+			err:    `unknown action "keepall"`,
+		},
+		"unknown relabel field": {
+			config: "relabel_configs: [{action: drop, source_label: [pod]}]",
+			err:    "field source_label not found",
+		},
+		"duplicate name": {
+			config: "targets: [{name: a, host: a:9093}, {name: a, host: b:9093}]",
+			err:    "duplicate name",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := config.Load(strings.NewReader(tc.config))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func ptr[T any](t T) *T { return &t }
+
+func TestTarget_ForTenant(t *testing.T) {
+	target := config.Target{
+		Name:    "mimir",
+		Tenants: []string{"team-a"},
+		ClientConfig: config.ClientConfig{
+			Headers: map[string]string{"X-Custom": "value"},
+		},
+	}
+
+	subject := target.ForTenant("team-a")
+	require.Equal(t, map[string]string{"X-Custom": "value", "X-Scope-OrgID": "team-a"}, subject.Headers)
+	require.Empty(t, subject.Tenants)
+	require.Equal(t, map[string]string{"X-Custom": "value"}, target.Headers, "original headers must not be modified")
+}
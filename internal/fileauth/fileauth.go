@@ -0,0 +1,108 @@
+package fileauth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+)
+
+// DefaultRefreshInterval is the interval at which files are reread if no interval is given.
+const DefaultRefreshInterval = 5 * time.Minute
+
+// NewFile creates a new File reading the given path and rereading it every refreshInterval.
+// If refreshInterval is 0, it defaults to DefaultRefreshInterval.
+// An error is returned if the initial read fails. Further read failures are logged and the last value is kept.
+func NewFile(path string, refreshInterval time.Duration) (*File, error) {
+	if refreshInterval == 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+
+	f := &File{
+		path:   path,
+		ticker: time.NewTicker(refreshInterval),
+	}
+
+	v, err := f.read()
+	if err != nil {
+		f.ticker.Stop()
+		return nil, fmt.Errorf("failed to read credentials from file: %w", err)
+	}
+	f.value.Store(v)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-f.ticker.C:
+				v, err := f.read()
+				if err != nil {
+					log.Printf("failed to read credentials from file %q: %v", f.path, err)
+					continue
+				}
+				f.value.Store(v)
+			}
+		}
+	}()
+
+	return f, nil
+}
+
+// File holds the content of a file containing credentials, such as a mounted secret.
+// The content is reread periodically so credentials can be rotated without a restart.
+// Leading and trailing whitespace is removed.
+type File struct {
+	path   string
+	value  atomic.Value
+	ticker *time.Ticker
+	cancel context.CancelFunc
+}
+
+// Load returns the last read content of the file.
+func (f *File) Load() string {
+	return f.value.Load().(string)
+}
+
+// Stop stops rereading the file.
+func (f *File) Stop() {
+	f.cancel()
+	f.ticker.Stop()
+}
+
+func (f *File) read() (string, error) {
+	b, err := os.ReadFile(f.path)
+	return strings.TrimSpace(string(b)), err
+}
+
+// BearerToken returns a runtime.ClientAuthInfoWriter setting the Authorization header to the token read from the given file.
+func BearerToken(token *File) runtime.ClientAuthInfoWriter {
+	return runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+		return r.SetHeaderParam(runtime.HeaderAuthorization, "Bearer "+token.Load())
+	})
+}
+
+// BasicAuth returns a runtime.ClientAuthInfoWriter setting the Authorization header to basic authentication with the given username and the password read from the given file.
+func BasicAuth(username string, password *File) runtime.ClientAuthInfoWriter {
+	return runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+		encoded := base64.StdEncoding.EncodeToString([]byte(username + ":" + password.Load()))
+		return r.SetHeaderParam(runtime.HeaderAuthorization, "Basic "+encoded)
+	})
+}
+
+// Header returns a runtime.ClientAuthInfoWriter setting the given header to the value read from the given file.
+func Header(name string, value *File) runtime.ClientAuthInfoWriter {
+	return runtime.ClientAuthInfoWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+		return r.SetHeaderParam(name, value.Load())
+	})
+}
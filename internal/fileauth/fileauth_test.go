@@ -0,0 +1,65 @@
+package fileauth_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appuio/alerts_exporter/internal/fileauth"
+)
+
+func Test_BearerToken(t *testing.T) {
+	tokenFile := t.TempDir() + "/token"
+	require.NoError(t, os.WriteFile(tokenFile, []byte("token\n"), 0644))
+
+	f, err := fileauth.NewFile(tokenFile, time.Millisecond)
+	require.NoError(t, err)
+	defer f.Stop()
+	subject := fileauth.BearerToken(f)
+
+	r := new(runtime.TestClientRequest)
+	require.NoError(t, subject.AuthenticateRequest(r, nil))
+	require.Equal(t, "Bearer token", r.GetHeaderParams().Get("Authorization"))
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("new-token"), 0644))
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		r := new(runtime.TestClientRequest)
+		assert.NoError(t, subject.AuthenticateRequest(r, nil))
+		assert.Equal(t, "Bearer new-token", r.GetHeaderParams().Get("Authorization"))
+	}, 5*time.Second, time.Millisecond)
+}
+
+func Test_BasicAuth(t *testing.T) {
+	passwordFile := t.TempDir() + "/password"
+	require.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0644))
+
+	f, err := fileauth.NewFile(passwordFile, 0)
+	require.NoError(t, err)
+	defer f.Stop()
+
+	r := new(runtime.TestClientRequest)
+	require.NoError(t, fileauth.BasicAuth("user", f).AuthenticateRequest(r, nil))
+	require.Equal(t, "Basic dXNlcjpzZWNyZXQ=", r.GetHeaderParams().Get("Authorization"))
+}
+
+func Test_Header(t *testing.T) {
+	valueFile := t.TempDir() + "/value"
+	require.NoError(t, os.WriteFile(valueFile, []byte("tenant-a"), 0644))
+
+	f, err := fileauth.NewFile(valueFile, 0)
+	require.NoError(t, err)
+	defer f.Stop()
+
+	r := new(runtime.TestClientRequest)
+	require.NoError(t, fileauth.Header("X-Scope-OrgID", f).AuthenticateRequest(r, nil))
+	require.Equal(t, "tenant-a", r.GetHeaderParams().Get("X-Scope-OrgID"))
+}
+
+func Test_NewFile_ReadErr(t *testing.T) {
+	_, err := fileauth.NewFile(t.TempDir()+"/missing", time.Millisecond)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
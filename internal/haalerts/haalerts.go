@@ -0,0 +1,290 @@
+package haalerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/go-openapi/runtime"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/slices"
+)
+
+// StatusPolicy decides which status is exported if peers disagree about the status of an alert.
+type StatusPolicy string
+
+const (
+	// MostSuppressed prefers suppressed over active over unprocessed alerts.
+	// An alert silenced or inhibited on any peer is exported as suppressed.
+	MostSuppressed StatusPolicy = "most-suppressed"
+	// LeastSuppressed prefers active over unprocessed over suppressed alerts.
+	LeastSuppressed StatusPolicy = "least-suppressed"
+	// FirstPeer prefers the status reported by the first peer in the list of peers.
+	FirstPeer StatusPolicy = "first-peer"
+)
+
+// ParseStatusPolicy parses the given status policy.
+// An empty string defaults to MostSuppressed.
+func ParseStatusPolicy(s string) (StatusPolicy, error) {
+	switch p := StatusPolicy(s); p {
+	case "":
+		return MostSuppressed, nil
+	case MostSuppressed, LeastSuppressed, FirstPeer:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown status policy %q", s)
+}
+
+func newDisagreeingDesc(constLabels prometheus.Labels) *prometheus.Desc {
+	return prometheus.NewDesc(
+		"alerts_exporter_ha_alerts_disagreeing",
+		"Number of alerts whose status differed between Alertmanager peers or that were missing on some peers.",
+		nil,
+		constLabels,
+	)
+}
+
+// AlertService queries all peers of an Alertmanager cluster and merges their alerts by fingerprint.
+// It implements alert.ClientService and can be used in place of a single Alertmanager's alert service.
+// It implements prometheus.Collector exporting the number of alerts the peers disagree on.
+type AlertService struct {
+	// Peers are the alert services of the Alertmanager cluster members.
+	Peers []alert.ClientService
+	// Policy decides which status wins if peers disagree. Defaults to MostSuppressed.
+	Policy StatusPolicy
+
+	// ConstLabels are added to the exported metric.
+	ConstLabels prometheus.Labels
+}
+
+var _ alert.ClientService = &AlertService{}
+var _ prometheus.Collector = &AlertService{}
+
+// GetAlerts implements alert.ClientService.
+// It queries all peers for alerts in any state and applies the state filters of the given params after merging.
+// Peers failing to respond are ignored. An error is returned only if all peers fail.
+func (s *AlertService) GetAlerts(params *alert.GetAlertsParams, opts ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+	if params == nil {
+		params = alert.NewGetAlertsParams()
+	}
+	merged, _, err := s.query(params, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &alert.GetAlertsOK{Payload: slices.DeleteFunc(merged, func(a *models.GettableAlert) bool {
+		return !matchesState(a, params)
+	})}, nil
+}
+
+// query queries all peers for alerts in any state matching the filters and receiver of the given params and merges them.
+// It returns the merged alerts and the number of alerts the peers disagreed on.
+func (s *AlertService) query(params *alert.GetAlertsParams, opts ...alert.ClientOption) ([]*models.GettableAlert, int, error) {
+	pp := *params
+	pp.Active, pp.Silenced, pp.Inhibited, pp.Unprocessed = nil, nil, nil, nil
+
+	results := make([][]*models.GettableAlert, len(s.Peers))
+	errs := make([]error, len(s.Peers))
+	var wg sync.WaitGroup
+	for i, peer := range s.Peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := peer.GetAlerts(&pp, opts...)
+			if err != nil {
+				errs[i] = fmt.Errorf("peer %d: %w", i, err)
+				return
+			}
+			results[i] = res.Payload
+		}()
+	}
+	wg.Wait()
+
+	var responding [][]*models.GettableAlert
+	for i := range s.Peers {
+		if errs[i] != nil {
+			log.Print("Error querying Alertmanager peer: ", errs[i])
+			continue
+		}
+		responding = append(responding, results[i])
+	}
+	if len(responding) == 0 {
+		return nil, 0, errors.Join(errs...)
+	}
+
+	merged, disagreeing := merge(responding, s.Policy)
+	return merged, disagreeing, nil
+}
+
+// PostAlerts implements alert.ClientService.
+// It posts the alerts to all peers and returns the first error.
+func (s *AlertService) PostAlerts(params *alert.PostAlertsParams, opts ...alert.ClientOption) (*alert.PostAlertsOK, error) {
+	var res *alert.PostAlertsOK
+	var errs []error
+	for _, peer := range s.Peers {
+		r, err := peer.PostAlerts(params, opts...)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res = r
+	}
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return res, nil
+}
+
+// SetTransport implements alert.ClientService.
+// It sets the transport of all peers.
+func (s *AlertService) SetTransport(transport runtime.ClientTransport) {
+	for _, peer := range s.Peers {
+		peer.SetTransport(transport)
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (s *AlertService) Describe(ch chan<- *prometheus.Desc) {
+	ch <- newDisagreeingDesc(s.ConstLabels)
+}
+
+// Collect implements prometheus.Collector.
+// It queries all peers for all alerts, independent of the filters of the queries exported by alerts collectors, and sends the number of alerts the peers disagree on.
+// The metric is not sent if all peers fail.
+func (s *AlertService) Collect(ch chan<- prometheus.Metric) {
+	_, disagreeing, err := s.query(alert.NewGetAlertsParamsWithContext(context.Background()))
+	if err != nil {
+		log.Print("Error querying Alertmanager peers for disagreeing alerts: ", err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(newDisagreeingDesc(s.ConstLabels), prometheus.GaugeValue, float64(disagreeing))
+}
+
+// merge merges the alerts of the given peers by fingerprint.
+// It returns the merged alerts in the order they were first seen and the number of alerts the peers disagreed on.
+func merge(peers [][]*models.GettableAlert, policy StatusPolicy) ([]*models.GettableAlert, int) {
+	type entry struct {
+		alert       *models.GettableAlert
+		seen        int
+		disagreeing bool
+	}
+
+	var order []string
+	entries := make(map[string]*entry)
+	for _, alerts := range peers {
+		for _, a := range alerts {
+			fp := fingerprint(a)
+			e, ok := entries[fp]
+			if !ok {
+				entries[fp] = &entry{alert: a, seen: 1}
+				order = append(order, fp)
+				continue
+			}
+			e.seen++
+			if !sameStatus(e.alert.Status, a.Status) {
+				e.disagreeing = true
+			}
+			if prefer(a, e.alert, policy) {
+				e.alert = a
+			}
+		}
+	}
+
+	merged := make([]*models.GettableAlert, 0, len(order))
+	disagreeing := 0
+	for _, fp := range order {
+		e := entries[fp]
+		if e.disagreeing || e.seen != len(peers) {
+			disagreeing++
+		}
+		merged = append(merged, e.alert)
+	}
+	return merged, disagreeing
+}
+
+// prefer returns true if the status of candidate should replace the status of current.
+func prefer(candidate, current *models.GettableAlert, policy StatusPolicy) bool {
+	switch policy {
+	case FirstPeer:
+		return false
+	case LeastSuppressed:
+		return leastSuppressedRank[state(candidate)] > leastSuppressedRank[state(current)]
+	default:
+		return mostSuppressedRank[state(candidate)] > mostSuppressedRank[state(current)]
+	}
+}
+
+// mostSuppressedRank ranks alert states for the MostSuppressed policy. Higher wins.
+var mostSuppressedRank = map[string]int{
+	models.AlertStatusStateUnprocessed: 1,
+	models.AlertStatusStateActive:      2,
+	models.AlertStatusStateSuppressed:  3,
+}
+
+// leastSuppressedRank ranks alert states for the LeastSuppressed policy. Higher wins.
+var leastSuppressedRank = map[string]int{
+	models.AlertStatusStateSuppressed:  1,
+	models.AlertStatusStateUnprocessed: 2,
+	models.AlertStatusStateActive:      3,
+}
+
+func matchesState(a *models.GettableAlert, params *alert.GetAlertsParams) bool {
+	st := state(a)
+	if st == models.AlertStatusStateActive && !enabled(params.Active) {
+		return false
+	}
+	if st == models.AlertStatusStateUnprocessed && !enabled(params.Unprocessed) {
+		return false
+	}
+	if a.Status != nil && len(a.Status.SilencedBy) > 0 && !enabled(params.Silenced) {
+		return false
+	}
+	if a.Status != nil && len(a.Status.InhibitedBy) > 0 && !enabled(params.Inhibited) {
+		return false
+	}
+	return true
+}
+
+func sameStatus(a, b *models.AlertStatus) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return deref(a.State) == deref(b.State) &&
+		sameSet(a.SilencedBy, b.SilencedBy) &&
+		sameSet(a.InhibitedBy, b.InhibitedBy)
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+func state(a *models.GettableAlert) string {
+	if a.Status == nil {
+		return ""
+	}
+	return deref(a.Status.State)
+}
+
+func fingerprint(a *models.GettableAlert) string {
+	return deref(a.Fingerprint)
+}
+
+// enabled returns the value of the given API flag. Unset flags default to true.
+func enabled(b *bool) bool {
+	return b == nil || *b
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
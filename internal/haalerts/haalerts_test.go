@@ -0,0 +1,180 @@
+package haalerts_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+	"github.com/appuio/alerts_exporter/internal/haalerts"
+)
+
+func TestAlertService_GetAlerts(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy   haalerts.StatusPolicy
+		silenced bool
+		expected map[string]string
+	}{
+		"most suppressed": {
+			policy:   haalerts.MostSuppressed,
+			silenced: true,
+			expected: map[string]string{"a": "suppressed", "b": "active", "c": "active"},
+		},
+		"most suppressed without silenced": {
+			policy:   haalerts.MostSuppressed,
+			silenced: false,
+			expected: map[string]string{"b": "active", "c": "active"},
+		},
+		"least suppressed": {
+			policy:   haalerts.LeastSuppressed,
+			silenced: true,
+			expected: map[string]string{"a": "active", "b": "active", "c": "active"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			peer0 := mock.NewMockClientService(ctrl)
+			peer0.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(&alert.GetAlertsOK{
+				Payload: []*models.GettableAlert{
+					newAlert("a", models.AlertStatusStateActive),
+					newAlert("b", models.AlertStatusStateActive),
+					newAlert("c", models.AlertStatusStateActive),
+				},
+			}, nil).Times(2)
+			peer1 := mock.NewMockClientService(ctrl)
+			peer1.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(&alert.GetAlertsOK{
+				Payload: []*models.GettableAlert{
+					newAlert("a", models.AlertStatusStateSuppressed, "d505b8d4-c5ce-466f-abd7-c704864299f5"),
+					newAlert("b", models.AlertStatusStateActive),
+				},
+			}, nil).Times(2)
+
+			subject := &haalerts.AlertService{
+				Peers:  []alert.ClientService{peer0, peer1},
+				Policy: tc.policy,
+			}
+
+			res, err := subject.GetAlerts(alert.NewGetAlertsParams().WithSilenced(&tc.silenced))
+			require.NoError(t, err)
+
+			states := make(map[string]string)
+			for _, a := range res.Payload {
+				states[*a.Fingerprint] = *a.Status.State
+			}
+			require.Equal(t, tc.expected, states)
+
+			require.NoError(t,
+				testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_ha_alerts_disagreeing Number of alerts whose status differed between Alertmanager peers or that were missing on some peers.
+# TYPE alerts_exporter_ha_alerts_disagreeing gauge
+alerts_exporter_ha_alerts_disagreeing 2
+`)),
+			)
+		})
+	}
+
+}
+
+func TestAlertService_Collect_Unfiltered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	// The peers only agree on the alerts matching the filter.
+	newPeer := func(as ...*models.GettableAlert) *mock.MockClientService {
+		peer := mock.NewMockClientService(ctrl)
+		peer.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).DoAndReturn(func(p *alert.GetAlertsParams, _ ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+			if len(p.Filter) > 0 {
+				return &alert.GetAlertsOK{Payload: []*models.GettableAlert{newAlert("b", models.AlertStatusStateActive)}}, nil
+			}
+			return &alert.GetAlertsOK{Payload: as}, nil
+		}).Times(2)
+		return peer
+	}
+	peer0 := newPeer(newAlert("a", models.AlertStatusStateActive), newAlert("b", models.AlertStatusStateActive))
+	peer1 := newPeer(newAlert("b", models.AlertStatusStateActive))
+
+	subject := &haalerts.AlertService{
+		Peers: []alert.ClientService{peer0, peer1},
+	}
+
+	_, err := subject.GetAlerts(alert.NewGetAlertsParams().WithFilter([]string{`alertname="b"`}))
+	require.NoError(t, err)
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_ha_alerts_disagreeing Number of alerts whose status differed between Alertmanager peers or that were missing on some peers.
+# TYPE alerts_exporter_ha_alerts_disagreeing gauge
+alerts_exporter_ha_alerts_disagreeing 1
+`)),
+	)
+}
+
+func TestAlertService_GetAlerts_PeerErr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	peer0 := mock.NewMockClientService(ctrl)
+	peer0.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(nil, errors.New("API error"))
+	peer1 := mock.NewMockClientService(ctrl)
+	peer1.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(&alert.GetAlertsOK{
+		Payload: []*models.GettableAlert{
+			newAlert("a", models.AlertStatusStateActive),
+		},
+	}, nil)
+
+	subject := &haalerts.AlertService{
+		Peers: []alert.ClientService{peer0, peer1},
+	}
+
+	res, err := subject.GetAlerts(nil)
+	require.NoError(t, err)
+	require.Len(t, res.Payload, 1)
+}
+
+func TestAlertService_GetAlerts_AllPeersErr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	peer0 := mock.NewMockClientService(ctrl)
+	peer0.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(nil, errors.New("API error 0"))
+	peer1 := mock.NewMockClientService(ctrl)
+	peer1.EXPECT().GetAlerts(gomock.Any(), gomock.Any()).Return(nil, errors.New("API error 1"))
+
+	subject := &haalerts.AlertService{
+		Peers: []alert.ClientService{peer0, peer1},
+	}
+
+	_, err := subject.GetAlerts(nil)
+	require.ErrorContains(t, err, "API error 0")
+	require.ErrorContains(t, err, "API error 1")
+}
+
+func TestParseStatusPolicy(t *testing.T) {
+	p, err := haalerts.ParseStatusPolicy("")
+	require.NoError(t, err)
+	require.Equal(t, haalerts.MostSuppressed, p)
+
+	p, err = haalerts.ParseStatusPolicy("first-peer")
+	require.NoError(t, err)
+	require.Equal(t, haalerts.FirstPeer, p)
+
+	_, err = haalerts.ParseStatusPolicy("random")
+	require.Error(t, err)
+}
+
+func newAlert(fingerprint, state string, silencedBy ...string) *models.GettableAlert {
+	return &models.GettableAlert{
+		Alert: models.Alert{
+			Labels: map[string]string{"alertname": fingerprint},
+		},
+		Fingerprint: &fingerprint,
+		Status: &models.AlertStatus{
+			State:      &state,
+			SilencedBy: silencedBy,
+		},
+	}
+}
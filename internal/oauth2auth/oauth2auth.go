@@ -0,0 +1,208 @@
+package oauth2auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+
+	"github.com/appuio/alerts_exporter/internal/fileauth"
+)
+
+// ExpiryDelta is how long before their expiry tokens are refreshed.
+const ExpiryDelta = 30 * time.Second
+
+// maxResponseSize limits the size of token responses read.
+const maxResponseSize = 1 << 20
+
+// NewClientCredentialsAuthInfoWriter creates a new ClientCredentialsAuthInfoWriter.
+// ClientCredentialsAuthInfoWriter implements the OAuth2 client credentials grant.
+// The client secret is read from the given file and reloaded periodically.
+// If client is nil, a client with a timeout of 30 seconds is used to request tokens.
+// An error is returned if the initial secret read fails. Tokens are requested on first use.
+func NewClientCredentialsAuthInfoWriter(tokenURL, clientID, clientSecretFile string, scopes []string, client *http.Client) (*ClientCredentialsAuthInfoWriter, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	secret, err := fileauth.NewFile(clientSecretFile, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client secret: %w", err)
+	}
+	return &ClientCredentialsAuthInfoWriter{
+		tokenURL: tokenURL,
+		clientID: clientID,
+		secret:   secret,
+		scopes:   scopes,
+		client:   client,
+	}, nil
+}
+
+// ClientCredentialsAuthInfoWriter implements OAuth2 client credentials authentication.
+// Tokens are cached until ExpiryDelta before they expire.
+type ClientCredentialsAuthInfoWriter struct {
+	tokenURL string
+	clientID string
+	secret   *fileauth.File
+	scopes   []string
+	client   *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// AuthenticateRequest implements the runtime.ClientAuthInfoWriter interface.
+// It sets the Authorization header to a cached token, requesting a new one if the cached token is about to expire.
+func (w *ClientCredentialsAuthInfoWriter) AuthenticateRequest(r runtime.ClientRequest, _ strfmt.Registry) error {
+	t, err := w.Token(context.Background())
+	if err != nil {
+		return err
+	}
+	return r.SetHeaderParam(runtime.HeaderAuthorization, "Bearer "+t)
+}
+
+// Token returns the cached token or requests a new one if the cached token is about to expire.
+func (w *ClientCredentialsAuthInfoWriter) Token(ctx context.Context) (string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.token != "" && (w.expiry.IsZero() || time.Now().Before(w.expiry.Add(-ExpiryDelta))) {
+		return w.token, nil
+	}
+	t, expiry, err := w.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	w.token, w.expiry = t, expiry
+	return t, nil
+}
+
+// invalidate drops the cached token if it is the given token.
+func (w *ClientCredentialsAuthInfoWriter) invalidate(token string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.token == token {
+		w.token = ""
+	}
+}
+
+// Stop stops reloading the client secret.
+func (w *ClientCredentialsAuthInfoWriter) Stop() {
+	w.secret.Stop()
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (w *ClientCredentialsAuthInfoWriter) requestToken(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(w.scopes) > 0 {
+		form.Set("scope", strings.Join(w.scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(w.clientID), url.QueryEscape(w.secret.Load()))
+
+	start := time.Now()
+	res, err := w.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer res.Body.Close()
+
+	var tr tokenResponse
+	if err := json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(&tr); err != nil && res.StatusCode == http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		if tr.Error != "" {
+			return "", time.Time{}, fmt.Errorf("token request failed with status %d: %s: %s", res.StatusCode, tr.Error, tr.ErrorDescription)
+		}
+		return "", time.Time{}, fmt.Errorf("token request failed with status %d", res.StatusCode)
+	}
+	if tr.AccessToken == "" {
+		return "", time.Time{}, errors.New("token response contains no access token")
+	}
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer") {
+		return "", time.Time{}, fmt.Errorf("unsupported token type %q", tr.TokenType)
+	}
+
+	var expiry time.Time
+	if tr.ExpiresIn > 0 {
+		expiry = start.Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return tr.AccessToken, expiry, nil
+}
+
+// Transport wraps the given round tripper to retry requests once with a new token if they are rejected with 401 Unauthorized.
+// Requests whose body cannot be replayed are not retried.
+func (w *ClientCredentialsAuthInfoWriter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &retryTransport{w: w, base: base}
+}
+
+type retryTransport struct {
+	w    *ClientCredentialsAuthInfoWriter
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.base.RoundTrip(req)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return res, nil
+	}
+
+	used, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return res, nil
+	}
+	t.w.invalidate(used)
+	token, err := t.w.Token(req.Context())
+	if err != nil {
+		// Return the original response, the token error is reported on the next request.
+		return res, nil
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return res, nil
+		}
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseSize))
+	res.Body.Close()
+	return t.base.RoundTrip(retry)
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped round tripper.
+func (t *retryTransport) CloseIdleConnections() {
+	if c, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
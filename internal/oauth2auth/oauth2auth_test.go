@@ -0,0 +1,120 @@
+package oauth2auth_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appuio/alerts_exporter/internal/oauth2auth"
+)
+
+// newTokenServer starts a stand-in token endpoint issuing the tokens 'token-1', 'token-2', ... valid for expiresIn seconds.
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var issued atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		id, secret, ok := req.BasicAuth()
+		if !ok || id != "exporter" || secret != "secret" {
+			res.Header().Set("Content-Type", "application/json")
+			res.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(res, `{"error": "invalid_client", "error_description": "bad credentials"}`)
+			return
+		}
+		if req.FormValue("grant_type") != "client_credentials" || req.FormValue("scope") != "alerts:read alerts:list" {
+			http.Error(res, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		res.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(res).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", issued.Add(1)),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &issued
+}
+
+func newSubject(t *testing.T, tokenURL, secret string) *oauth2auth.ClientCredentialsAuthInfoWriter {
+	t.Helper()
+	secretFile := t.TempDir() + "/secret"
+	require.NoError(t, os.WriteFile(secretFile, []byte(secret), 0600))
+	subject, err := oauth2auth.NewClientCredentialsAuthInfoWriter(tokenURL, "exporter", secretFile, []string{"alerts:read", "alerts:list"}, nil)
+	require.NoError(t, err)
+	t.Cleanup(subject.Stop)
+	return subject
+}
+
+func Test_ClientCredentialsAuthInfoWriter_AuthenticateRequest(t *testing.T) {
+	srv, issued := newTokenServer(t, 3600)
+	subject := newSubject(t, srv.URL, "secret")
+
+	for i := 0; i < 3; i++ {
+		r := new(runtime.TestClientRequest)
+		require.NoError(t, subject.AuthenticateRequest(r, nil))
+		require.Equal(t, "Bearer token-1", r.GetHeaderParams().Get("Authorization"))
+	}
+	require.EqualValues(t, 1, issued.Load(), "token should be cached")
+}
+
+func Test_ClientCredentialsAuthInfoWriter_AuthenticateRequest_Expiry(t *testing.T) {
+	srv, issued := newTokenServer(t, int(oauth2auth.ExpiryDelta.Seconds()))
+	subject := newSubject(t, srv.URL, "secret")
+
+	for i := 1; i <= 2; i++ {
+		r := new(runtime.TestClientRequest)
+		require.NoError(t, subject.AuthenticateRequest(r, nil))
+		require.Equal(t, fmt.Sprintf("Bearer token-%d", i), r.GetHeaderParams().Get("Authorization"))
+	}
+	require.EqualValues(t, 2, issued.Load(), "tokens about to expire should be refreshed")
+}
+
+func Test_ClientCredentialsAuthInfoWriter_AuthenticateRequest_Err(t *testing.T) {
+	srv, _ := newTokenServer(t, 3600)
+	subject := newSubject(t, srv.URL, "wrong")
+
+	require.ErrorContains(t, subject.AuthenticateRequest(new(runtime.TestClientRequest), nil), "invalid_client: bad credentials")
+}
+
+func Test_ClientCredentialsAuthInfoWriter_Transport(t *testing.T) {
+	tokenSrv, issued := newTokenServer(t, 3600)
+	subject := newSubject(t, tokenSrv.URL, "secret")
+
+	// The API only accepts the second token, as if the first was revoked.
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token-2" {
+			http.Error(res, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(res, "ok")
+	}))
+	defer apiSrv.Close()
+
+	token, err := subject.Token(t.Context())
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodGet, apiSrv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := (&http.Client{Transport: subject.Transport(nil)}).Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.EqualValues(t, 2, issued.Load())
+
+	token, err = subject.Token(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "token-2", token, "the new token should be cached")
+}
+
+func Test_NewClientCredentialsAuthInfoWriter_SecretReadErr(t *testing.T) {
+	_, err := oauth2auth.NewClientCredentialsAuthInfoWriter("http://localhost", "exporter", t.TempDir()+"/secret", nil, nil)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
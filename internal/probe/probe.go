@@ -0,0 +1,126 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/appuio/alerts_exporter/internal/amclient"
+	"github.com/appuio/alerts_exporter/internal/config"
+)
+
+// DefaultModule is the module used if the request does not specify one.
+const DefaultModule = "default"
+
+// scrapeTimeoutOffset is subtracted from the scrape timeout sent by Prometheus so the response arrives before Prometheus gives up.
+const scrapeTimeoutOffset = 500 * time.Millisecond
+
+// Handler serves the alerts of the Alertmanager given in the 'target' query parameter.
+// The connection settings are taken from the module given in the 'module' query parameter.
+// The transport of each module, including its authentication, is created on first use and shared by all targets.
+type Handler struct {
+	// Modules are the available connection settings by name.
+	Modules map[string]config.ClientConfig
+	// NewCollectors creates the collectors exporting the alerts of the given alert service.
+	NewCollectors func(alert.ClientService) []prometheus.Collector
+
+	mu         sync.Mutex
+	transports map[string]*amclient.Transport
+}
+
+// ServeHTTP implements http.Handler.
+// The Alertmanager is queried with the request's context, limited by the 'X-Prometheus-Scrape-Timeout-Seconds' header if present.
+func (h *Handler) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	target := req.URL.Query().Get("target")
+	if target == "" {
+		http.Error(res, "Target parameter is missing", http.StatusBadRequest)
+		return
+	}
+	moduleName := req.URL.Query().Get("module")
+	if moduleName == "" {
+		moduleName = DefaultModule
+	}
+	module, ok := h.Modules[moduleName]
+	if !ok {
+		http.Error(res, fmt.Sprintf("Unknown module %q", moduleName), http.StatusBadRequest)
+		return
+	}
+
+	tr, err := h.transport(moduleName, module)
+	if err != nil {
+		http.Error(res, fmt.Sprintf("Failed to create client for module %q: %s", moduleName, err), http.StatusInternalServerError)
+		return
+	}
+
+	ctx := req.Context()
+	if t, err := strconv.ParseFloat(req.Header.Get("X-Prometheus-Scrape-Timeout-Seconds"), 64); err == nil && t > 0 {
+		timeout := time.Duration(t * float64(time.Second))
+		if timeout > scrapeTimeoutOffset {
+			timeout -= scrapeTimeoutOffset
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	reg := prometheus.NewRegistry()
+	for _, c := range h.NewCollectors(contextAlertService{ClientService: tr.Client(target).Alert, ctx: ctx}) {
+		if err := reg.Register(c); err != nil {
+			http.Error(res, "Failed to register collector: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	promhttp.HandlerFor(reg, promhttp.HandlerOpts{}).ServeHTTP(res, req)
+}
+
+// Stop releases the transports of all modules.
+func (h *Handler) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, tr := range h.transports {
+		tr.Stop()
+	}
+	h.transports = nil
+}
+
+// transport returns the transport of the given module, creating it on first use.
+func (h *Handler) transport(name string, module config.ClientConfig) (*amclient.Transport, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if tr, ok := h.transports[name]; ok {
+		return tr, nil
+	}
+	tr, err := amclient.NewTransport(module)
+	if err != nil {
+		return nil, err
+	}
+	if h.transports == nil {
+		h.transports = make(map[string]*amclient.Transport)
+	}
+	h.transports[name] = tr
+	return tr, nil
+}
+
+// contextAlertService queries alerts with the given context instead of the context of the query parameters.
+type contextAlertService struct {
+	alert.ClientService
+	ctx context.Context
+}
+
+// GetAlerts implements alert.ClientService.
+func (s contextAlertService) GetAlerts(params *alert.GetAlertsParams, opts ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+	if params == nil {
+		params = alert.NewGetAlertsParams()
+	}
+	p := *params
+	p.Context = s.ctx
+	return s.ClientService.GetAlerts(&p, opts...)
+}
@@ -0,0 +1,137 @@
+package probe_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/config"
+	"github.com/appuio/alerts_exporter/internal/probe"
+)
+
+func newCollectors(as alert.ClientService) []prometheus.Collector {
+	return []prometheus.Collector{&alertscollector.AlertsCollector{AlertService: as}}
+}
+
+func TestHandler(t *testing.T) {
+	am := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"labels":{"alertname":"Test"}}]`))
+	}))
+	defer am.Close()
+
+	subject := &probe.Handler{
+		Modules: map[string]config.ClientConfig{
+			"default": {},
+			"token":   {BearerToken: "token"},
+		},
+		NewCollectors: newCollectors,
+	}
+	defer subject.Stop()
+
+	target := strings.TrimPrefix(am.URL, "http://")
+
+	res := httptest.NewRecorder()
+	subject.ServeHTTP(res, httptest.NewRequest("GET", "/probe?"+url.Values{"target": {target}, "module": {"token"}}.Encode(), nil))
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `alerts_exporter_alerts{alertname="Test"} 1`)
+
+	res = httptest.NewRecorder()
+	subject.ServeHTTP(res, httptest.NewRequest("GET", "/probe?"+url.Values{"target": {target}}.Encode(), nil))
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `alerts_exporter_up 0`)
+	require.Contains(t, res.Body.String(), `alerts_exporter_scrape_errors_total{class="auth"} 1`)
+}
+
+func TestHandler_BadRequest(t *testing.T) {
+	subject := &probe.Handler{
+		Modules: map[string]config.ClientConfig{
+			"default": {},
+		},
+	}
+
+	res := httptest.NewRecorder()
+	subject.ServeHTTP(res, httptest.NewRequest("GET", "/probe", nil))
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	require.Contains(t, res.Body.String(), "Target parameter is missing")
+
+	res = httptest.NewRecorder()
+	subject.ServeHTTP(res, httptest.NewRequest("GET", "/probe?target=localhost:9093&module=unknown", nil))
+	require.Equal(t, http.StatusBadRequest, res.Code)
+	require.Contains(t, res.Body.String(), `Unknown module "unknown"`)
+}
+
+func TestHandler_SharedTransport(t *testing.T) {
+	secretFile := t.TempDir() + "/secret"
+	require.NoError(t, os.WriteFile(secretFile, []byte("secret"), 0600))
+
+	var issued atomic.Int64
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer tokenSrv.Close()
+	am := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	defer am.Close()
+
+	subject := &probe.Handler{
+		Modules: map[string]config.ClientConfig{
+			"oauth2": {OAuth2: &config.OAuth2{TokenURL: tokenSrv.URL, ClientID: "exporter", ClientSecretFile: secretFile}},
+		},
+		NewCollectors: newCollectors,
+	}
+	defer subject.Stop()
+
+	for range 3 {
+		res := httptest.NewRecorder()
+		subject.ServeHTTP(res, httptest.NewRequest("GET", "/probe?"+url.Values{"target": {strings.TrimPrefix(am.URL, "http://")}, "module": {"oauth2"}}.Encode(), nil))
+		require.Equal(t, http.StatusOK, res.Code)
+		require.Contains(t, res.Body.String(), `alerts_exporter_up 1`)
+	}
+	require.EqualValues(t, 1, issued.Load(), "the token should be shared by all probes of the module")
+}
+
+func TestHandler_ScrapeTimeout(t *testing.T) {
+	am := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer am.Close()
+
+	subject := &probe.Handler{
+		Modules: map[string]config.ClientConfig{
+			"default": {},
+		},
+		NewCollectors: newCollectors,
+	}
+	defer subject.Stop()
+
+	req := httptest.NewRequest("GET", "/probe?"+url.Values{"target": {strings.TrimPrefix(am.URL, "http://")}}.Encode(), nil)
+	req.Header.Set("X-Prometheus-Scrape-Timeout-Seconds", "0.1")
+	res := httptest.NewRecorder()
+	start := time.Now()
+	subject.ServeHTTP(res, req)
+	require.Less(t, time.Since(start), 5*time.Second, "the query should be canceled at the scrape timeout")
+	require.Equal(t, http.StatusOK, res.Code)
+	require.Contains(t, res.Body.String(), `alerts_exporter_scrape_errors_total{class="timeout"} 1`)
+}
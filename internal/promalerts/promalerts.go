@@ -0,0 +1,170 @@
+package promalerts
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	openapiclient "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+)
+
+// DefaultBasePath is the base path of the Prometheus and Thanos Ruler HTTP API.
+const DefaultBasePath = "/api/v1"
+
+// StatePending is the state of alerts whose rule expression is true for less than the rule's 'for' duration.
+// Alertmanager never receives pending alerts.
+const StatePending = "pending"
+
+// Rule evaluator alert states as returned by the API.
+const (
+	ruleStateFiring  = "firing"
+	ruleStatePending = "pending"
+)
+
+// Source queries the alerts of a Prometheus or Thanos Ruler from its '/api/v1/alerts' endpoint.
+// Firing alerts are returned in the 'active' state, pending alerts in the StatePending state.
+type Source struct {
+	// Transport is used to query the API. Its base path must point to the v1 API, see DefaultBasePath.
+	Transport runtime.ClientTransport
+}
+
+var _ alertscollector.AlertSource = &Source{}
+
+// Alert is an alert returned by the '/api/v1/alerts' endpoint.
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	ActiveAt    *time.Time        `json:"activeAt,omitempty"`
+	Value       string            `json:"value"`
+}
+
+type response struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		Alerts []Alert `json:"alerts"`
+	} `json:"data"`
+}
+
+// GetAlerts implements alertscollector.AlertSource.
+// The filters of the given params are applied. Firing alerts are skipped if Active is false.
+// Pending alerts are always returned. Silenced, Inhibited and Unprocessed are ignored as rule evaluators do not suppress alerts.
+// Filtering by receiver is not supported and returns an error.
+func (s *Source) GetAlerts(params *alert.GetAlertsParams, _ ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+	if params == nil {
+		params = alert.NewGetAlertsParams()
+	}
+	if params.Receiver != nil {
+		return nil, errors.New("filtering by receiver is not supported for Prometheus alert sources")
+	}
+	matchers := make([]*labels.Matcher, 0, len(params.Filter))
+	for _, f := range params.Filter {
+		m, err := labels.ParseMatcher(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", f, err)
+		}
+		matchers = append(matchers, m)
+	}
+
+	res, err := s.Transport.Submit(&runtime.ClientOperation{
+		ID:                 "getRuleAlerts",
+		Method:             "GET",
+		PathPattern:        "/alerts",
+		ProducesMediaTypes: []string{"application/json"},
+		ConsumesMediaTypes: []string{"application/json"},
+		Schemes:            []string{"http"},
+		Params: runtime.ClientRequestWriterFunc(func(r runtime.ClientRequest, _ strfmt.Registry) error {
+			return r.SetTimeout(openapiclient.DefaultTimeout)
+		}),
+		Reader:  runtime.ClientResponseReaderFunc(readResponse),
+		Context: params.Context,
+		Client:  params.HTTPClient,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	active := params.Active == nil || *params.Active
+	as := make([]*models.GettableAlert, 0)
+	for _, a := range res.([]Alert) {
+		switch {
+		case a.State == ruleStateFiring && !active:
+			continue
+		case a.State != ruleStateFiring && a.State != ruleStatePending:
+			continue
+		case !matches(a, matchers):
+			continue
+		}
+		as = append(as, newGettableAlert(a))
+	}
+	return &alert.GetAlertsOK{Payload: as}, nil
+}
+
+func readResponse(r runtime.ClientResponse, consumer runtime.Consumer) (interface{}, error) {
+	var res response
+	if err := consumer.Consume(r.Body(), &res); err != nil {
+		if r.Code() != 200 {
+			return nil, runtime.NewAPIError("[GET /alerts] getRuleAlerts", r, r.Code())
+		}
+		return nil, fmt.Errorf("failed to decode alerts: %w", err)
+	}
+	if r.Code() != 200 {
+		// Keep the status code so errors are classified like errors of the Alertmanager API.
+		return nil, runtime.NewAPIError("[GET /alerts] getRuleAlerts", fmt.Errorf("%s: %s", res.ErrorType, res.Error), r.Code())
+	}
+	if res.Status != "success" {
+		return nil, fmt.Errorf("[GET /alerts] getRuleAlerts: %s: %s", res.ErrorType, res.Error)
+	}
+	return res.Data.Alerts, nil
+}
+
+func matches(a Alert, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(a.Labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func newGettableAlert(a Alert) *models.GettableAlert {
+	state := models.AlertStatusStateActive
+	if a.State == ruleStatePending {
+		state = StatePending
+	}
+	fp := toLabelSet(a.Labels).Fingerprint().String()
+	ga := &models.GettableAlert{
+		Alert:       models.Alert{Labels: a.Labels},
+		Annotations: a.Annotations,
+		Fingerprint: &fp,
+		Receivers:   []*models.Receiver{},
+		Status: &models.AlertStatus{
+			State:       &state,
+			SilencedBy:  []string{},
+			InhibitedBy: []string{},
+		},
+	}
+	if a.ActiveAt != nil {
+		startsAt := strfmt.DateTime(*a.ActiveAt)
+		ga.StartsAt = &startsAt
+	}
+	return ga
+}
+
+func toLabelSet(m map[string]string) model.LabelSet {
+	ls := make(model.LabelSet, len(m))
+	for k, v := range m {
+		ls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return ls
+}
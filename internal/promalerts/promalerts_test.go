@@ -0,0 +1,128 @@
+package promalerts_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	openapiclient "github.com/go-openapi/runtime/client"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/promalerts"
+)
+
+const alertsResponse = `{
+  "status": "success",
+  "data": {
+    "alerts": [
+      {"labels": {"alertname": "Firing", "severity": "critical"}, "annotations": {"summary": "Firing"}, "state": "firing", "activeAt": "2023-11-14T22:13:20Z", "value": "1e+00"},
+      {"labels": {"alertname": "Pending", "severity": "warning"}, "annotations": {}, "state": "pending", "activeAt": "2023-11-14T22:13:20Z", "value": "1e+00"}
+    ]
+  }
+}`
+
+func newSource(t *testing.T, status int, body string) *promalerts.Source {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		require.Equal(t, "/api/v1/alerts", req.URL.Path)
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(status)
+		fmt.Fprint(res, body)
+	}))
+	t.Cleanup(srv.Close)
+	return &promalerts.Source{
+		Transport: openapiclient.New(strings.TrimPrefix(srv.URL, "http://"), promalerts.DefaultBasePath, nil),
+	}
+}
+
+func TestSource_GetAlerts(t *testing.T) {
+	subject := newSource(t, http.StatusOK, alertsResponse)
+
+	as, err := subject.GetAlerts(alert.NewGetAlertsParams())
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 2)
+	require.Equal(t, "Firing", as.Payload[0].Labels["alertname"])
+	require.Equal(t, "active", *as.Payload[0].Status.State)
+	require.Equal(t, "Firing", as.Payload[0].Annotations["summary"])
+	require.Equal(t, "2023-11-14T22:13:20.000Z", as.Payload[0].StartsAt.String())
+	require.NotEmpty(t, *as.Payload[0].Fingerprint)
+	require.Equal(t, "Pending", as.Payload[1].Labels["alertname"])
+	require.Equal(t, promalerts.StatePending, *as.Payload[1].Status.State)
+
+	as, err = subject.GetAlerts(alert.NewGetAlertsParams().WithFilter([]string{`severity=~"crit.*"`}))
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 1)
+	require.Equal(t, "Firing", as.Payload[0].Labels["alertname"])
+
+	f := false
+	as, err = subject.GetAlerts(alert.NewGetAlertsParams().WithActive(&f))
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 1)
+	require.Equal(t, "Pending", as.Payload[0].Labels["alertname"])
+
+	receiver := "team-a"
+	_, err = subject.GetAlerts(alert.NewGetAlertsParams().WithReceiver(&receiver))
+	require.ErrorContains(t, err, "receiver is not supported")
+
+	_, err = subject.GetAlerts(alert.NewGetAlertsParams().WithFilter([]string{`severity`}))
+	require.ErrorContains(t, err, "invalid filter")
+}
+
+func TestSource_GetAlerts_Error(t *testing.T) {
+	subject := newSource(t, http.StatusServiceUnavailable, `{"status": "error", "errorType": "unavailable", "error": "not ready"}`)
+
+	_, err := subject.GetAlerts(alert.NewGetAlertsParams())
+	require.ErrorContains(t, err, "unavailable: not ready")
+
+	var apiErr *runtime.APIError
+	require.ErrorAs(t, err, &apiErr)
+	require.True(t, apiErr.IsCode(http.StatusServiceUnavailable))
+
+	for status, class := range map[int]string{
+		http.StatusServiceUnavailable:  alertscollector.ErrorClassHTTPStatus,
+		http.StatusUnprocessableEntity: alertscollector.ErrorClassHTTPStatus,
+		http.StatusForbidden:           alertscollector.ErrorClassAuth,
+	} {
+		subject := &alertscollector.AlertsCollector{
+			AlertService: newSource(t, status, `{"status": "error", "errorType": "execution", "error": "failed"}`),
+		}
+		expected := `
+# HELP alerts_exporter_scrape_errors_total Number of failed queries to the Alertmanager API by error class.
+# TYPE alerts_exporter_scrape_errors_total counter
+`
+		for _, c := range []string{alertscollector.ErrorClassAuth, alertscollector.ErrorClassConnection, alertscollector.ErrorClassHTTPStatus, alertscollector.ErrorClassOther, alertscollector.ErrorClassTimeout, alertscollector.ErrorClassTLS} {
+			v := 0
+			if c == class {
+				v = 1
+			}
+			expected += fmt.Sprintf("alerts_exporter_scrape_errors_total{class=%q} %d\n", c, v)
+		}
+		require.NoError(t,
+			testutil.CollectAndCompare(subject, strings.NewReader(expected), "alerts_exporter_scrape_errors_total"),
+			"status %d", status,
+		)
+	}
+}
+
+func TestSource_AlertsCollector(t *testing.T) {
+	subject := &alertscollector.AlertsCollector{
+		AlertService: newSource(t, http.StatusOK, alertsResponse),
+	}
+
+	expected := `
+# HELP alerts_exporter_alerts Alerts queried from the Alertmanager API. Alert state can be found in the '_alerts_exporter_alert_state' label.
+# TYPE alerts_exporter_alerts gauge
+alerts_exporter_alerts{_alerts_exporter_alert_state="active",alertname="Firing",severity="critical"} 1
+alerts_exporter_alerts{_alerts_exporter_alert_state="pending",alertname="Pending",severity="warning"} 1
+`
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(expected), "alerts_exporter_alerts"),
+	)
+}
@@ -0,0 +1,230 @@
+package relabel
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// Action is the action taken by a relabel config.
+type Action string
+
+const (
+	// Replace sets TargetLabel to Replacement if Regex matches the concatenated SourceLabels.
+	// The label is removed if the expanded replacement is empty.
+	Replace Action = "replace"
+	// Keep drops alerts for which Regex does not match the concatenated SourceLabels.
+	Keep Action = "keep"
+	// Drop drops alerts for which Regex matches the concatenated SourceLabels.
+	Drop Action = "drop"
+	// HashMod sets TargetLabel to the modulus of a hash of the concatenated SourceLabels.
+	HashMod Action = "hashmod"
+	// LabelMap copies labels whose names match Regex to the names given by Replacement.
+	LabelMap Action = "labelmap"
+	// LabelDrop removes labels whose names match Regex.
+	LabelDrop Action = "labeldrop"
+	// LabelKeep removes labels whose names do not match Regex.
+	LabelKeep Action = "labelkeep"
+)
+
+// Default values of Config fields as in Prometheus.
+const (
+	DefaultSeparator   = ";"
+	DefaultRegex       = "(.*)"
+	DefaultReplacement = "$1"
+)
+
+// Config is a Prometheus-style relabel config applied to the labels of an alert.
+type Config struct {
+	// SourceLabels select values from existing labels. Their values are concatenated using Separator.
+	SourceLabels []string `yaml:"source_labels"`
+	// Separator placed between concatenated source label values. Defaults to DefaultSeparator.
+	Separator string `yaml:"separator"`
+	// Regex matched against the concatenated source label values or, for the label actions, against label names.
+	// It is fully anchored. Defaults to DefaultRegex.
+	Regex Regex `yaml:"regex"`
+	// Modulus to take of the hash of the source label values.
+	Modulus uint64 `yaml:"modulus"`
+	// TargetLabel is the label written by the replace and hashmod actions.
+	// Regex capture groups are available for the replace action.
+	TargetLabel string `yaml:"target_label"`
+	// Replacement against which a regex replace is performed if the regex matches. Defaults to DefaultReplacement.
+	Replacement string `yaml:"replacement"`
+	// Action to perform based on regex matching. Defaults to Replace.
+	Action Action `yaml:"action"`
+}
+
+var configFields = []string{"source_labels", "separator", "regex", "modulus", "target_label", "replacement", "action"}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+// It applies the default values, rejects unknown fields and validates the config.
+func (c *Config) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		for i := 0; i < len(value.Content); i += 2 {
+			k := value.Content[i]
+			if !slices.Contains(configFields, k.Value) {
+				return fmt.Errorf("line %d: field %s not found in relabel config", k.Line, k.Value)
+			}
+		}
+	}
+
+	*c = Config{
+		Separator:   DefaultSeparator,
+		Regex:       MustNewRegex(DefaultRegex),
+		Replacement: DefaultReplacement,
+		Action:      Replace,
+	}
+	type plain Config
+	if err := value.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("line %d: invalid relabel config: %w", value.Line, err)
+	}
+	return nil
+}
+
+// Validate checks the config for errors.
+// Configs not read from YAML must set all fields explicitly, no defaults are applied.
+func (c *Config) Validate() error {
+	if c.Regex.Regexp == nil {
+		return errors.New("regex is required")
+	}
+	switch c.Action {
+	case Replace:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("target_label is required for action %q", c.Action)
+		}
+	case HashMod:
+		if !model.LabelName(c.TargetLabel).IsValidLegacy() {
+			return fmt.Errorf("invalid target_label %q for action %q", c.TargetLabel, c.Action)
+		}
+		if c.Modulus == 0 {
+			return fmt.Errorf("modulus is required for action %q", c.Action)
+		}
+	case Keep, Drop, LabelMap:
+	case LabelDrop, LabelKeep:
+		if len(c.SourceLabels) > 0 || c.TargetLabel != "" {
+			return fmt.Errorf("source_labels and target_label are not allowed for action %q", c.Action)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", c.Action)
+	}
+	return nil
+}
+
+// Regex is a fully anchored regular expression unmarshalled from a YAML string.
+type Regex struct {
+	*regexp.Regexp
+}
+
+// NewRegex compiles the given expression anchored at both ends.
+func NewRegex(s string) (Regex, error) {
+	re, err := regexp.Compile("^(?:" + s + ")$")
+	return Regex{Regexp: re}, err
+}
+
+// MustNewRegex is like NewRegex but panics if the expression cannot be compiled.
+func MustNewRegex(s string) Regex {
+	re, err := NewRegex(s)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (re *Regex) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	r, err := NewRegex(s)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid regex: %w", value.Line, err)
+	}
+	*re = r
+	return nil
+}
+
+// Process applies the given relabel configs to the labels in order.
+// The labels are modified in place. ok is false if the alert should be dropped.
+// Labels with invalid names resulting from a replacement are not written.
+func Process(labels map[string]string, cfgs ...*Config) (ok bool) {
+	for _, c := range cfgs {
+		if !process(labels, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func process(labels map[string]string, c *Config) bool {
+	values := make([]string, len(c.SourceLabels))
+	for i, l := range c.SourceLabels {
+		values[i] = labels[l]
+	}
+	val := strings.Join(values, c.Separator)
+
+	switch c.Action {
+	case Drop:
+		if c.Regex.MatchString(val) {
+			return false
+		}
+	case Keep:
+		if !c.Regex.MatchString(val) {
+			return false
+		}
+	case Replace:
+		indexes := c.Regex.FindStringSubmatchIndex(val)
+		if indexes == nil {
+			break
+		}
+		target := string(c.Regex.ExpandString(nil, c.TargetLabel, val, indexes))
+		if !model.LabelName(target).IsValidLegacy() {
+			break
+		}
+		res := c.Regex.ExpandString(nil, c.Replacement, val, indexes)
+		if len(res) == 0 {
+			delete(labels, target)
+			break
+		}
+		labels[target] = string(res)
+	case HashMod:
+		sum := md5.Sum([]byte(val))
+		labels[c.TargetLabel] = fmt.Sprint(binary.BigEndian.Uint64(sum[8:]) % c.Modulus)
+	case LabelMap:
+		mapped := make(map[string]string)
+		for k, v := range labels {
+			if !c.Regex.MatchString(k) {
+				continue
+			}
+			if n := c.Regex.ReplaceAllString(k, c.Replacement); model.LabelName(n).IsValidLegacy() {
+				mapped[n] = v
+			}
+		}
+		for k, v := range mapped {
+			labels[k] = v
+		}
+	case LabelDrop:
+		for k := range labels {
+			if c.Regex.MatchString(k) {
+				delete(labels, k)
+			}
+		}
+	case LabelKeep:
+		for k := range labels {
+			if !c.Regex.MatchString(k) {
+				delete(labels, k)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+package relabel_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/appuio/alerts_exporter/internal/relabel"
+)
+
+func TestProcess(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config string
+		labels map[string]string
+		want   map[string]string
+	}{
+		"drop": {
+			config: `[{source_labels: [severity], regex: info, action: drop}]`,
+			labels: map[string]string{"alertname": "A", "severity": "info"},
+			want:   nil,
+		},
+		"drop no match": {
+			config: `[{source_labels: [severity], regex: info, action: drop}]`,
+			labels: map[string]string{"alertname": "A", "severity": "critical"},
+			want:   map[string]string{"alertname": "A", "severity": "critical"},
+		},
+		"keep": {
+			config: `[{source_labels: [namespace, severity], regex: "prod-.*;critical", action: keep}]`,
+			labels: map[string]string{"namespace": "prod-a", "severity": "warning"},
+			want:   nil,
+		},
+		"replace": {
+			config: `[{source_labels: [namespace], regex: "(.+)-(.+)", target_label: environment, replacement: "$1"}]`,
+			labels: map[string]string{"namespace": "prod-a"},
+			want:   map[string]string{"namespace": "prod-a", "environment": "prod"},
+		},
+		"replace empty removes label": {
+			config: `[{target_label: pod, replacement: ""}]`,
+			labels: map[string]string{"alertname": "A", "pod": "a-0"},
+			want:   map[string]string{"alertname": "A"},
+		},
+		"hashmod": {
+			config: `[{source_labels: [alertname], target_label: shard, modulus: 4, action: hashmod}]`,
+			labels: map[string]string{"alertname": "A"},
+			want:   map[string]string{"alertname": "A", "shard": "1"},
+		},
+		"labelmap": {
+			config: `[{regex: "label_(.+)", action: labelmap}]`,
+			labels: map[string]string{"label_team": "a", "label_app-name": "b"},
+			want:   map[string]string{"label_team": "a", "team": "a", "label_app-name": "b"},
+		},
+		"labeldrop": {
+			config: `[{regex: "pod|instance", action: labeldrop}]`,
+			labels: map[string]string{"alertname": "A", "pod": "a-0", "instance": "a:80"},
+			want:   map[string]string{"alertname": "A"},
+		},
+		"labelkeep": {
+			config: `[{regex: "alertname|severity", action: labelkeep}]`,
+			labels: map[string]string{"alertname": "A", "severity": "info", "pod": "a-0"},
+			want:   map[string]string{"alertname": "A", "severity": "info"},
+		},
+		"in order": {
+			config: `[{source_labels: [pod], target_label: old_pod}, {regex: pod, action: labeldrop}]`,
+			labels: map[string]string{"pod": "a-0"},
+			want:   map[string]string{"old_pod": "a-0"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var cfgs []*relabel.Config
+			require.NoError(t, yaml.Unmarshal([]byte(tc.config), &cfgs))
+
+			ok := relabel.Process(tc.labels, cfgs...)
+			if tc.want == nil {
+				require.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			require.Equal(t, tc.want, tc.labels)
+		})
+	}
+}
+
+func TestConfig_UnmarshalYAML_Invalid(t *testing.T) {
+	for name, tc := range map[string]struct {
+		config string
+		err    string
+	}{
+		"unknown action": {
+			config: `{action: keepall}`,
+			err:    `unknown action "keepall"`,
+		},
+		"unknown field": {
+			config: `{action: keep, regexp: a}`,
+			err:    "field regexp not found",
+		},
+		"invalid regex": {
+			config: `{action: keep, regex: "("}`,
+			err:    "invalid regex",
+		},
+		"missing modulus": {
+			config: `{action: hashmod, target_label: shard}`,
+			err:    "modulus is required",
+		},
+		"labeldrop with source labels": {
+			config: `{action: labeldrop, source_labels: [pod]}`,
+			err:    "not allowed",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var c relabel.Config
+			require.ErrorContains(t, yaml.Unmarshal([]byte(tc.config), &c), tc.err)
+		})
+	}
+}
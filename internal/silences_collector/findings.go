@@ -0,0 +1,77 @@
+package silencescollector
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// broadProbes are label values a regex must match to be considered to match any value.
+var broadProbes = []string{"a", "Z", "0", "-", " ", "some-long_value.with/special:chars"}
+
+// collectFindings sends metrics flagging silences that are likely forgotten or hide more alerts than intended.
+// The stale flag is skipped if matched is nil.
+func (o *SilencesCollector) collectFindings(ch chan<- prometheus.Metric, s *models.GettableSilence, matched map[string]int, keys, values []string) error {
+	type finding struct {
+		name, help string
+		v          bool
+	}
+	findings := []finding{
+		{"alerts_exporter_silence_without_comment", "Whether silences queried from the Alertmanager API were created without a comment.", strings.TrimSpace(deref(s.Comment)) == ""},
+		{"alerts_exporter_silence_broad", "Whether silences queried from the Alertmanager API only have matchers matching any value.", isBroad(s.Matchers)},
+	}
+	if matched != nil {
+		active := s.Status != nil && deref(s.Status.State) == models.SilenceStatusStateActive
+		findings = append(findings, finding{"alerts_exporter_silence_stale", "Whether silences queried from the Alertmanager API are active but currently silence no alerts.", active && matched[deref(s.ID)] == 0})
+	}
+
+	for _, f := range findings {
+		v := 0.0
+		if f.v {
+			v = 1
+		}
+		m, err := prometheus.NewConstMetric(
+			prometheus.NewDesc(f.name, f.help, keys, o.ConstLabels),
+			prometheus.GaugeValue,
+			v,
+			values...,
+		)
+		if err != nil {
+			return err
+		}
+		ch <- m
+	}
+	return nil
+}
+
+// isBroad returns true if none of the given matchers restricts the matched alerts to specific label values.
+// Negative matchers and regex matchers matching any value are not restrictive.
+func isBroad(ms models.Matchers) bool {
+	for _, m := range ms {
+		if m != nil && !isBroadMatcher(m) {
+			return false
+		}
+	}
+	return true
+}
+
+func isBroadMatcher(m *models.Matcher) bool {
+	if m.IsEqual != nil && !*m.IsEqual {
+		return true
+	}
+	if !deref(m.IsRegex) {
+		return false
+	}
+	re, err := regexp.Compile("^(?:" + deref(m.Value) + ")$")
+	if err != nil {
+		return false
+	}
+	for _, p := range broadProbes {
+		if !re.MatchString(p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package silencescollector_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertsmock "github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+	silencescollector "github.com/appuio/alerts_exporter/internal/silences_collector"
+	"github.com/appuio/alerts_exporter/internal/silences_collector/mock"
+)
+
+func TestSilencesCollector_Findings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	newSilence := func(id, state, comment string, ms ...*models.Matcher) *models.GettableSilence {
+		return &models.GettableSilence{
+			ID:     ptr(id),
+			Status: &models.SilenceStatus{State: ptr(state)},
+			Silence: models.Silence{
+				Comment:  ptr(comment),
+				Matchers: ms,
+			},
+		}
+	}
+	matcher := func(name, value string, regex, equal bool) *models.Matcher {
+		return &models.Matcher{Name: ptr(name), Value: ptr(value), IsRegex: ptr(regex), IsEqual: ptr(equal)}
+	}
+
+	mockSilenceService := mock.NewMockClientService(ctrl)
+	mockSilenceService.
+		EXPECT().
+		GetSilences(gomock.Any(), gomock.Any()).
+		Return(&silence.GetSilencesOK{
+			Payload: models.GettableSilences{
+				newSilence("matching", models.SilenceStatusStateActive, "Maintenance", matcher("alertname", "ImportantAlert", false, true)),
+				newSilence("stale", models.SilenceStatusStateActive, "Maintenance", matcher("alertname", "OldAlert", false, true)),
+				newSilence("no-comment", models.SilenceStatusStatePending, "  ", matcher("alertname", "OldAlert", false, true)),
+				newSilence("broad", models.SilenceStatusStateActive, "Too noisy", matcher("severity", ".+", true, true), matcher("namespace", "test", false, false)),
+				newSilence("restricted-regex", models.SilenceStatusStateExpired, "Too noisy", matcher("severity", ".+", true, true), matcher("namespace", "test-.*", true, true)),
+			},
+		}, nil)
+
+	mockAlertService := alertsmock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		Return(&alert.GetAlertsOK{
+			Payload: []*models.GettableAlert{
+				{Status: &models.AlertStatus{SilencedBy: []string{"matching", "broad"}}},
+			},
+		}, nil)
+
+	subject := &silencescollector.SilencesCollector{
+		SilenceService: mockSilenceService,
+		AlertService:   mockAlertService,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_silence_broad Whether silences queried from the Alertmanager API only have matchers matching any value.
+# TYPE alerts_exporter_silence_broad gauge
+alerts_exporter_silence_broad{silence_id="broad",state="active"} 1
+alerts_exporter_silence_broad{silence_id="matching",state="active"} 0
+alerts_exporter_silence_broad{silence_id="no-comment",state="pending"} 0
+alerts_exporter_silence_broad{silence_id="restricted-regex",state="expired"} 0
+alerts_exporter_silence_broad{silence_id="stale",state="active"} 0
+# HELP alerts_exporter_silence_stale Whether silences queried from the Alertmanager API are active but currently silence no alerts.
+# TYPE alerts_exporter_silence_stale gauge
+alerts_exporter_silence_stale{silence_id="broad",state="active"} 0
+alerts_exporter_silence_stale{silence_id="matching",state="active"} 0
+alerts_exporter_silence_stale{silence_id="no-comment",state="pending"} 0
+alerts_exporter_silence_stale{silence_id="restricted-regex",state="expired"} 0
+alerts_exporter_silence_stale{silence_id="stale",state="active"} 1
+# HELP alerts_exporter_silence_without_comment Whether silences queried from the Alertmanager API were created without a comment.
+# TYPE alerts_exporter_silence_without_comment gauge
+alerts_exporter_silence_without_comment{silence_id="broad",state="active"} 0
+alerts_exporter_silence_without_comment{silence_id="matching",state="active"} 0
+alerts_exporter_silence_without_comment{silence_id="no-comment",state="pending"} 1
+alerts_exporter_silence_without_comment{silence_id="restricted-regex",state="expired"} 0
+alerts_exporter_silence_without_comment{silence_id="stale",state="active"} 0
+`),
+			"alerts_exporter_silence_broad", "alerts_exporter_silence_stale", "alerts_exporter_silence_without_comment",
+		),
+	)
+}
@@ -0,0 +1,198 @@
+package silencescollector
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+
+	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+)
+
+// SilencesCollector exports the silences of an Alertmanager.
+// Every series is labeled with the silence's ID and state.
+type SilencesCollector struct {
+	SilenceService silence.ClientService
+	// AlertService is used to count the alerts each silence matches.
+	// All silenced alerts are queried on every collection, without filters or receiver, so the count is consistent with the collected silences.
+	// The count and the stale finding are not exported if nil or if the query fails.
+	AlertService alertscollector.AlertSource
+
+	// ConstLabels are added to every exported series.
+	ConstLabels prometheus.Labels
+}
+
+var _ prometheus.Collector = &SilencesCollector{}
+
+// Describe implements prometheus.Collector.
+// Does not send any description and thus makes the collector unchecked.
+func (o *SilencesCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+// It queries the Alertmanager API and sends metrics for each silence.
+// The scrape does not fail if the query fails, the outcome is exported in 'alerts_exporter_silences_up'.
+func (o *SilencesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+
+	ss, err := o.SilenceService.GetSilences(silence.NewGetSilencesParamsWithContext(ctx))
+	if err != nil {
+		log.Print("Error querying Alertmanager silences", err)
+	}
+	up := 0.0
+	if err == nil {
+		up = 1
+	}
+	ch <- prometheus.MustNewConstMetric(
+		prometheus.NewDesc("alerts_exporter_silences_up", "Whether the last query to the Alertmanager silences API succeeded.", nil, o.ConstLabels),
+		prometheus.GaugeValue,
+		up,
+	)
+	if err != nil {
+		return
+	}
+
+	var matched map[string]int
+	if o.AlertService != nil {
+		matched, err = o.matchedAlerts(ctx)
+		if err != nil {
+			log.Print("Error querying Alertmanager for silenced alerts", err)
+		}
+	}
+
+	for _, s := range ss.Payload {
+		if err := o.collectSilence(ch, s, matched); err != nil {
+			log.Print("Error exporting silence", err)
+		}
+	}
+}
+
+// matchedAlerts returns the number of alerts silenced by each silence ID.
+func (o *SilencesCollector) matchedAlerts(ctx context.Context) (map[string]int, error) {
+	f := false
+	t := true
+	as, err := o.AlertService.GetAlerts(alert.NewGetAlertsParamsWithContext(ctx).
+		WithActive(&f).
+		WithSilenced(&t).
+		WithInhibited(&t).
+		WithUnprocessed(&f))
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make(map[string]int)
+	for _, a := range as.Payload {
+		if a.Status == nil {
+			continue
+		}
+		for _, id := range a.Status.SilencedBy {
+			matched[id]++
+		}
+	}
+	return matched, nil
+}
+
+// collectSilence sends the metrics of the given silence.
+// The matched alerts count is skipped if matched is nil.
+func (o *SilencesCollector) collectSilence(ch chan<- prometheus.Metric, s *models.GettableSilence, matched map[string]int) error {
+	keys := []string{"silence_id", "state"}
+	values := []string{deref(s.ID), ""}
+	if s.Status != nil {
+		values[1] = deref(s.Status.State)
+	}
+
+	m, err := prometheus.NewConstMetric(
+		prometheus.NewDesc(
+			"alerts_exporter_silence_info",
+			"Silences queried from the Alertmanager API.",
+			append(keys, "created_by", "matchers"),
+			o.ConstLabels,
+		),
+		prometheus.GaugeValue,
+		1,
+		append(values, deref(s.CreatedBy), formatMatchers(s.Matchers))...,
+	)
+	if err != nil {
+		return err
+	}
+	ch <- m
+
+	for _, ts := range []struct {
+		name, help string
+		t          *strfmt.DateTime
+	}{
+		{"alerts_exporter_silence_starts_at_timestamp_seconds", "Unix timestamp at which silences queried from the Alertmanager API start.", s.StartsAt},
+		{"alerts_exporter_silence_ends_at_timestamp_seconds", "Unix timestamp at which silences queried from the Alertmanager API end.", s.EndsAt},
+	} {
+		if ts.t == nil || time.Time(*ts.t).IsZero() {
+			continue
+		}
+		m, err := prometheus.NewConstMetric(
+			prometheus.NewDesc(ts.name, ts.help, keys, o.ConstLabels),
+			prometheus.GaugeValue,
+			float64(time.Time(*ts.t).UnixNano())/1e9,
+			values...,
+		)
+		if err != nil {
+			return err
+		}
+		ch <- m
+	}
+
+	if err := o.collectFindings(ch, s, matched, keys, values); err != nil {
+		return err
+	}
+
+	if matched == nil {
+		return nil
+	}
+	m, err = prometheus.NewConstMetric(
+		prometheus.NewDesc("alerts_exporter_silence_matched_alerts", "Number of alerts currently silenced by silences queried from the Alertmanager API.", keys, o.ConstLabels),
+		prometheus.GaugeValue,
+		float64(matched[deref(s.ID)]),
+		values...,
+	)
+	if err != nil {
+		return err
+	}
+	ch <- m
+	return nil
+}
+
+// formatMatchers formats the given matchers like the matchers of an Alertmanager filter, for example 'alertname="A",severity=~"crit.*"'.
+func formatMatchers(ms models.Matchers) string {
+	f := make([]string, 0, len(ms))
+	for _, m := range ms {
+		if m == nil {
+			continue
+		}
+		equal := m.IsEqual == nil || *m.IsEqual
+		var op string
+		switch regex := deref(m.IsRegex); {
+		case equal && !regex:
+			op = "="
+		case !equal && !regex:
+			op = "!="
+		case equal && regex:
+			op = "=~"
+		default:
+			op = "!~"
+		}
+		f = append(f, fmt.Sprintf("%s%s%q", deref(m.Name), op, deref(m.Value)))
+	}
+	return strings.Join(f, ",")
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,134 @@
+package silencescollector_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	alertsmock "github.com/appuio/alerts_exporter/internal/alerts_collector/mock"
+	silencescollector "github.com/appuio/alerts_exporter/internal/silences_collector"
+	"github.com/appuio/alerts_exporter/internal/silences_collector/mock"
+)
+
+//go:generate go run github.com/golang/mock/mockgen -destination=./mock/silence_service.go -package mock github.com/prometheus/alertmanager/api/v2/client/silence ClientService
+
+func TestSilencesCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockSilenceService := mock.NewMockClientService(ctrl)
+	mockSilenceService.
+		EXPECT().
+		GetSilences(gomock.Any(), gomock.Any()).
+		Return(&silence.GetSilencesOK{
+			Payload: models.GettableSilences{
+				{
+					ID:     ptr("a"),
+					Status: &models.SilenceStatus{State: ptr(models.SilenceStatusStateActive)},
+					Silence: models.Silence{
+						CreatedBy: ptr("on-call"),
+						Matchers: models.Matchers{
+							{Name: ptr("alertname"), Value: ptr("ImportantAlert"), IsRegex: ptr(false)},
+							{Name: ptr("severity"), Value: ptr("crit.*"), IsRegex: ptr(true)},
+							{Name: ptr("namespace"), Value: ptr("test"), IsRegex: ptr(false), IsEqual: ptr(false)},
+						},
+						StartsAt: ptr(strfmt.DateTime(time.Unix(1700000000, 0))),
+						EndsAt:   ptr(strfmt.DateTime(time.Unix(1700003600, 0))),
+					},
+				},
+				{
+					ID:     ptr("b"),
+					Status: &models.SilenceStatus{State: ptr(models.SilenceStatusStatePending)},
+					Silence: models.Silence{
+						CreatedBy: ptr("maintenance"),
+						Matchers: models.Matchers{
+							{Name: ptr("cluster"), Value: ptr("c-.*"), IsRegex: ptr(true), IsEqual: ptr(false)},
+						},
+						StartsAt: ptr(strfmt.DateTime(time.Unix(1800000000, 0))),
+						EndsAt:   ptr(strfmt.DateTime(time.Unix(1800003600, 0))),
+					},
+				},
+			},
+		}, nil)
+
+	mockAlertService := alertsmock.NewMockClientService(ctrl)
+	mockAlertService.
+		EXPECT().
+		GetAlerts(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(p *alert.GetAlertsParams, _ ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+			require.False(t, *p.Active)
+			require.True(t, *p.Silenced)
+			require.Empty(t, p.Filter, "all silenced alerts should be counted")
+			require.Nil(t, p.Receiver, "all silenced alerts should be counted")
+			return &alert.GetAlertsOK{
+				Payload: []*models.GettableAlert{
+					{Status: &models.AlertStatus{SilencedBy: []string{"a"}}},
+					{Status: &models.AlertStatus{SilencedBy: []string{"a", "c"}}},
+				},
+			}, nil
+		})
+
+	subject := &silencescollector.SilencesCollector{
+		SilenceService: mockSilenceService,
+		AlertService:   mockAlertService,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_silence_info Silences queried from the Alertmanager API.
+# TYPE alerts_exporter_silence_info gauge
+alerts_exporter_silence_info{created_by="on-call",matchers="alertname=\"ImportantAlert\",severity=~\"crit.*\",namespace!=\"test\"",silence_id="a",state="active"} 1
+alerts_exporter_silence_info{created_by="maintenance",matchers="cluster!~\"c-.*\"",silence_id="b",state="pending"} 1
+# HELP alerts_exporter_silence_starts_at_timestamp_seconds Unix timestamp at which silences queried from the Alertmanager API start.
+# TYPE alerts_exporter_silence_starts_at_timestamp_seconds gauge
+alerts_exporter_silence_starts_at_timestamp_seconds{silence_id="a",state="active"} 1.7e+09
+alerts_exporter_silence_starts_at_timestamp_seconds{silence_id="b",state="pending"} 1.8e+09
+# HELP alerts_exporter_silence_ends_at_timestamp_seconds Unix timestamp at which silences queried from the Alertmanager API end.
+# TYPE alerts_exporter_silence_ends_at_timestamp_seconds gauge
+alerts_exporter_silence_ends_at_timestamp_seconds{silence_id="a",state="active"} 1.7000036e+09
+alerts_exporter_silence_ends_at_timestamp_seconds{silence_id="b",state="pending"} 1.8000036e+09
+# HELP alerts_exporter_silence_matched_alerts Number of alerts currently silenced by silences queried from the Alertmanager API.
+# TYPE alerts_exporter_silence_matched_alerts gauge
+alerts_exporter_silence_matched_alerts{silence_id="a",state="active"} 2
+alerts_exporter_silence_matched_alerts{silence_id="b",state="pending"} 0
+# HELP alerts_exporter_silences_up Whether the last query to the Alertmanager silences API succeeded.
+# TYPE alerts_exporter_silences_up gauge
+alerts_exporter_silences_up 1
+`),
+			"alerts_exporter_silence_info", "alerts_exporter_silence_starts_at_timestamp_seconds", "alerts_exporter_silence_ends_at_timestamp_seconds", "alerts_exporter_silence_matched_alerts", "alerts_exporter_silences_up",
+		),
+	)
+}
+
+func TestSilencesCollector_Err(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	mockSilenceService := mock.NewMockClientService(ctrl)
+	mockSilenceService.
+		EXPECT().
+		GetSilences(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("API error"))
+
+	subject := &silencescollector.SilencesCollector{
+		SilenceService: mockSilenceService,
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_silences_up Whether the last query to the Alertmanager silences API succeeded.
+# TYPE alerts_exporter_silences_up gauge
+alerts_exporter_silences_up 0
+`),
+		),
+	)
+}
+
+func ptr[T any](t T) *T { return &t }
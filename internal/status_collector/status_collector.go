@@ -0,0 +1,168 @@
+package statuscollector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/client/general"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// clusterStatuses are the cluster states exported for every instance.
+var clusterStatuses = []string{models.ClusterStatusStatusReady, models.ClusterStatusStatusSettling, models.ClusterStatusStatusDisabled}
+
+var errNilResponse = errors.New("nil response from Alertmanager")
+
+// Instance is an Alertmanager whose status is exported.
+type Instance struct {
+	// Name identifies the instance and is added as 'alertmanager_instance' label.
+	// The label is not named 'instance' to not collide with the target label added by Prometheus.
+	Name           string
+	GeneralService general.ClientService
+}
+
+// StatusCollector exports the status of Alertmanagers, such as their version, cluster state and loaded configuration.
+// Every series is labeled with the instance's name.
+type StatusCollector struct {
+	// Instances are the Alertmanagers to query. For clusters all peers should be listed to detect divergence.
+	Instances []Instance
+
+	// ConstLabels are added to every exported series.
+	ConstLabels prometheus.Labels
+}
+
+var _ prometheus.Collector = &StatusCollector{}
+
+// Describe implements prometheus.Collector.
+// Does not send any description and thus makes the collector unchecked.
+func (o *StatusCollector) Describe(_ chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+// It queries the status API of every instance.
+// The scrape does not fail if a query fails, the outcome is exported in 'alerts_exporter_alertmanager_up'.
+func (o *StatusCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, i := range o.Instances {
+		s, err := i.GeneralService.GetStatus(general.NewGetStatusParamsWithContext(context.Background()))
+		if err == nil && (s == nil || s.Payload == nil) {
+			err = errNilResponse
+		}
+		if err != nil {
+			log.Printf("Error querying Alertmanager %q status: %v", i.Name, err)
+		}
+
+		up := 0.0
+		if err == nil {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(
+			prometheus.NewDesc("alerts_exporter_alertmanager_up", "Whether the last query to the Alertmanager status API succeeded.", []string{"alertmanager_instance"}, o.ConstLabels),
+			prometheus.GaugeValue,
+			up,
+			i.Name,
+		)
+		if err != nil {
+			continue
+		}
+
+		if err := o.collectStatus(ch, i.Name, s.Payload); err != nil {
+			log.Printf("Error exporting Alertmanager %q status: %v", i.Name, err)
+		}
+	}
+}
+
+// collectStatus sends the metrics describing the given status of an instance.
+func (o *StatusCollector) collectStatus(ch chan<- prometheus.Metric, instance string, s *models.AlertmanagerStatus) error {
+	var metrics []prometheus.Metric
+	gauge := func(name, help string, v float64, labels ...string) error {
+		keys := []string{"alertmanager_instance"}
+		values := []string{instance}
+		for i := 0; i+1 < len(labels); i += 2 {
+			keys = append(keys, labels[i])
+			values = append(values, labels[i+1])
+		}
+		m, err := prometheus.NewConstMetric(prometheus.NewDesc(name, help, keys, o.ConstLabels), prometheus.GaugeValue, v, values...)
+		if err != nil {
+			return err
+		}
+		metrics = append(metrics, m)
+		return nil
+	}
+
+	v := s.VersionInfo
+	if v == nil {
+		v = &models.VersionInfo{}
+	}
+	c := s.Cluster
+	if c == nil {
+		c = &models.ClusterStatus{}
+	}
+
+	if err := gauge("alerts_exporter_alertmanager_info", "Version and cluster name of the Alertmanager.", 1,
+		"version", deref(v.Version),
+		"revision", deref(v.Revision),
+		"branch", deref(v.Branch),
+		"go_version", deref(v.GoVersion),
+		"cluster_name", c.Name,
+	); err != nil {
+		return err
+	}
+
+	for _, st := range clusterStatuses {
+		isStatus := 0.0
+		if deref(c.Status) == st {
+			isStatus = 1
+		}
+		if err := gauge("alerts_exporter_alertmanager_cluster_status", "Cluster status of the Alertmanager. One of ready, settling or disabled.", isStatus, "status", st); err != nil {
+			return err
+		}
+	}
+
+	if err := gauge("alerts_exporter_alertmanager_cluster_peers", "Number of peers of the Alertmanager's cluster, including itself.", float64(len(c.Peers))); err != nil {
+		return err
+	}
+	for _, p := range c.Peers {
+		if p == nil {
+			continue
+		}
+		if err := gauge("alerts_exporter_alertmanager_cluster_peer_info", "Peers of the Alertmanager's cluster, including itself.", 1, "peer_name", deref(p.Name), "peer_address", deref(p.Address)); err != nil {
+			return err
+		}
+	}
+
+	if s.Uptime != nil && !time.Time(*s.Uptime).IsZero() {
+		if err := gauge("alerts_exporter_alertmanager_start_time_seconds", "Unix timestamp at which the Alertmanager was started.", float64(time.Time(*s.Uptime).UnixNano())/1e9); err != nil {
+			return err
+		}
+	}
+
+	if s.Config != nil && s.Config.Original != nil {
+		if err := gauge("alerts_exporter_alertmanager_config_hash", "Hash of the configuration loaded by the Alertmanager. Differs between peers with diverging configurations.", configHash(*s.Config.Original)); err != nil {
+			return err
+		}
+	}
+
+	for _, m := range metrics {
+		ch <- m
+	}
+	return nil
+}
+
+// configHash returns a hash of the given configuration.
+// The hash is truncated to 48 bits to be exactly representable as a float.
+func configHash(c string) float64 {
+	sum := sha256.Sum256([]byte(c))
+	return float64(binary.BigEndian.Uint64(sum[:8]) >> 16)
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
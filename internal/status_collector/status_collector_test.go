@@ -0,0 +1,101 @@
+package statuscollector_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/alertmanager/api/v2/client/general"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	statuscollector "github.com/appuio/alerts_exporter/internal/status_collector"
+	"github.com/appuio/alerts_exporter/internal/status_collector/mock"
+)
+
+//go:generate go run github.com/golang/mock/mockgen -destination=./mock/general_service.go -package mock github.com/prometheus/alertmanager/api/v2/client/general ClientService
+
+func TestStatusCollector(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	newStatus := func(config string) *general.GetStatusOK {
+		return &general.GetStatusOK{
+			Payload: &models.AlertmanagerStatus{
+				VersionInfo: &models.VersionInfo{
+					Version:   ptr("0.27.0"),
+					Revision:  ptr("0aa3c2a"),
+					Branch:    ptr("HEAD"),
+					GoVersion: ptr("go1.21.7"),
+				},
+				Cluster: &models.ClusterStatus{
+					Name:   "01HQ",
+					Status: ptr(models.ClusterStatusStatusSettling),
+					Peers: []*models.PeerStatus{
+						{Name: ptr("01HQ"), Address: ptr("10.0.0.1:9094")},
+					},
+				},
+				Uptime: ptr(strfmt.DateTime(time.Unix(1700000000, 0))),
+				Config: &models.AlertmanagerConfig{Original: ptr(config)},
+			},
+		}
+	}
+
+	peer0 := mock.NewMockClientService(ctrl)
+	peer0.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Times(2).Return(newStatus("route: {receiver: a}"), nil)
+	peer1 := mock.NewMockClientService(ctrl)
+	peer1.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Times(2).Return(newStatus("route: {receiver: b}"), nil)
+	peer2 := mock.NewMockClientService(ctrl)
+	peer2.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Times(2).Return(nil, errors.New("API error"))
+
+	subject := &statuscollector.StatusCollector{
+		Instances: []statuscollector.Instance{
+			{Name: "alertmanager-0:9093", GeneralService: peer0},
+			{Name: "alertmanager-1:9093", GeneralService: peer1},
+			{Name: "alertmanager-2:9093", GeneralService: peer2},
+		},
+	}
+
+	require.NoError(t,
+		testutil.CollectAndCompare(subject, strings.NewReader(`
+# HELP alerts_exporter_alertmanager_cluster_peer_info Peers of the Alertmanager's cluster, including itself.
+# TYPE alerts_exporter_alertmanager_cluster_peer_info gauge
+alerts_exporter_alertmanager_cluster_peer_info{alertmanager_instance="alertmanager-0:9093",peer_address="10.0.0.1:9094",peer_name="01HQ"} 1
+alerts_exporter_alertmanager_cluster_peer_info{alertmanager_instance="alertmanager-1:9093",peer_address="10.0.0.1:9094",peer_name="01HQ"} 1
+# HELP alerts_exporter_alertmanager_cluster_peers Number of peers of the Alertmanager's cluster, including itself.
+# TYPE alerts_exporter_alertmanager_cluster_peers gauge
+alerts_exporter_alertmanager_cluster_peers{alertmanager_instance="alertmanager-0:9093"} 1
+alerts_exporter_alertmanager_cluster_peers{alertmanager_instance="alertmanager-1:9093"} 1
+# HELP alerts_exporter_alertmanager_cluster_status Cluster status of the Alertmanager. One of ready, settling or disabled.
+# TYPE alerts_exporter_alertmanager_cluster_status gauge
+alerts_exporter_alertmanager_cluster_status{alertmanager_instance="alertmanager-0:9093",status="disabled"} 0
+alerts_exporter_alertmanager_cluster_status{alertmanager_instance="alertmanager-0:9093",status="ready"} 0
+alerts_exporter_alertmanager_cluster_status{alertmanager_instance="alertmanager-0:9093",status="settling"} 1
+alerts_exporter_alertmanager_cluster_status{alertmanager_instance="alertmanager-1:9093",status="disabled"} 0
+alerts_exporter_alertmanager_cluster_status{alertmanager_instance="alertmanager-1:9093",status="ready"} 0
+alerts_exporter_alertmanager_cluster_status{alertmanager_instance="alertmanager-1:9093",status="settling"} 1
+# HELP alerts_exporter_alertmanager_info Version and cluster name of the Alertmanager.
+# TYPE alerts_exporter_alertmanager_info gauge
+alerts_exporter_alertmanager_info{alertmanager_instance="alertmanager-0:9093",branch="HEAD",cluster_name="01HQ",go_version="go1.21.7",revision="0aa3c2a",version="0.27.0"} 1
+alerts_exporter_alertmanager_info{alertmanager_instance="alertmanager-1:9093",branch="HEAD",cluster_name="01HQ",go_version="go1.21.7",revision="0aa3c2a",version="0.27.0"} 1
+# HELP alerts_exporter_alertmanager_start_time_seconds Unix timestamp at which the Alertmanager was started.
+# TYPE alerts_exporter_alertmanager_start_time_seconds gauge
+alerts_exporter_alertmanager_start_time_seconds{alertmanager_instance="alertmanager-0:9093"} 1.7e+09
+alerts_exporter_alertmanager_start_time_seconds{alertmanager_instance="alertmanager-1:9093"} 1.7e+09
+# HELP alerts_exporter_alertmanager_up Whether the last query to the Alertmanager status API succeeded.
+# TYPE alerts_exporter_alertmanager_up gauge
+alerts_exporter_alertmanager_up{alertmanager_instance="alertmanager-0:9093"} 1
+alerts_exporter_alertmanager_up{alertmanager_instance="alertmanager-1:9093"} 1
+alerts_exporter_alertmanager_up{alertmanager_instance="alertmanager-2:9093"} 0
+`),
+			"alerts_exporter_alertmanager_cluster_peer_info", "alerts_exporter_alertmanager_cluster_peers", "alerts_exporter_alertmanager_cluster_status", "alerts_exporter_alertmanager_info", "alerts_exporter_alertmanager_start_time_seconds", "alerts_exporter_alertmanager_up",
+		),
+	)
+
+	require.Equal(t, 2, testutil.CollectAndCount(subject, "alerts_exporter_alertmanager_config_hash"))
+}
+
+func ptr[T any](t T) *T { return &t }
@@ -0,0 +1,285 @@
+package webhook
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/runtime"
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"golang.org/x/exp/slices"
+)
+
+// DefaultTTL is the time after which alerts are forgotten if Store.TTL is zero.
+// It must be longer than the 'repeat_interval' of the Alertmanager routes, which defaults to 4h.
+const DefaultTTL = 6 * time.Hour
+
+// DefaultMaxAlerts is the maximum number of stored alerts if Store.MaxAlerts is zero.
+const DefaultMaxAlerts = 10000
+
+// maxPayloadSize limits the size of accepted notification payloads.
+const maxPayloadSize = 10 << 20
+
+// Message is the payload of an Alertmanager webhook notification.
+type Message struct {
+	Version  string  `json:"version"`
+	Status   string  `json:"status"`
+	Receiver string  `json:"receiver"`
+	Alerts   []Alert `json:"alerts"`
+}
+
+// Alert is an alert of an Alertmanager webhook notification.
+type Alert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// Store keeps the alerts received from Alertmanager webhook notifications in memory.
+// It implements http.Handler to receive notifications and alert.ClientService to serve the alerts to the collectors.
+// Alerts are removed when they are resolved, when their EndsAt passed or if no notification was received for TTL.
+type Store struct {
+	// TTL is the time after which alerts are removed if no notification was received for them. Defaults to DefaultTTL.
+	TTL time.Duration
+	// MaxAlerts limits the number of stored alerts. New alerts are dropped once the limit is reached. Defaults to DefaultMaxAlerts.
+	MaxAlerts int
+	// Authenticate checks the credentials of notifications. Notifications are accepted without credentials if nil.
+	Authenticate Authenticator
+
+	mu     sync.Mutex
+	alerts map[string]*entry
+}
+
+type entry struct {
+	alert      *models.GettableAlert
+	receivedAt time.Time
+}
+
+var _ alert.ClientService = &Store{}
+var _ http.Handler = &Store{}
+
+// Authenticator reports whether the request carries valid credentials.
+type Authenticator func(req *http.Request) bool
+
+// BearerToken returns an Authenticator accepting requests with the bearer token returned by token.
+// This matches the 'authorization' setting of the Alertmanager webhook 'http_config'.
+func BearerToken(token func() string) Authenticator {
+	return func(req *http.Request) bool {
+		return equal(req.Header.Get("Authorization"), "Bearer "+token())
+	}
+}
+
+// BasicAuth returns an Authenticator accepting requests with the given username and the password returned by password.
+// This matches the 'basic_auth' setting of the Alertmanager webhook 'http_config'.
+func BasicAuth(username string, password func() string) Authenticator {
+	return func(req *http.Request) bool {
+		u, p, ok := req.BasicAuth()
+		// Both are compared to not leak which one is wrong through timing.
+		return ok && equal(u, username) && equal(p, password())
+	}
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// ServeHTTP implements http.Handler.
+// It accepts Alertmanager webhook notifications and updates the stored alerts.
+func (s *Store) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(res, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Authenticate != nil && !s.Authenticate(req) {
+		http.Error(res, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var m Message
+	if err := json.NewDecoder(http.MaxBytesReader(res, req.Body, maxPayloadSize)).Decode(&m); err != nil {
+		http.Error(res, fmt.Sprintf("invalid notification: %v", err), http.StatusBadRequest)
+		return
+	}
+	if dropped := s.Receive(m, time.Now()); dropped > 0 {
+		log.Printf("webhook: dropped %d alerts of receiver %q, the limit of %d stored alerts is reached", dropped, m.Receiver, s.maxAlerts())
+		http.Error(res, fmt.Sprintf("dropped %d alerts, the limit of stored alerts is reached", dropped), http.StatusInsufficientStorage)
+	}
+}
+
+// Receive updates the stored alerts with the given notification received at the given time.
+// It returns the number of new alerts dropped because MaxAlerts was reached. Already stored alerts are always updated.
+func (s *Store) Receive(m Message, now time.Time) (dropped int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.alerts == nil {
+		s.alerts = make(map[string]*entry)
+	}
+	s.expire(now)
+
+	for _, a := range m.Alerts {
+		fp := a.Fingerprint
+		if fp == "" {
+			fp = toLabelSet(a.Labels).Fingerprint().String()
+		}
+		if a.Status == string(model.AlertResolved) {
+			delete(s.alerts, fp)
+			continue
+		}
+
+		ga := newGettableAlert(fp, a, now)
+		var receivers []*models.Receiver
+		if e, ok := s.alerts[fp]; ok {
+			receivers = e.alert.Receivers
+		} else if len(s.alerts) >= s.maxAlerts() {
+			dropped++
+			continue
+		}
+		ga.Receivers = mergeReceivers(receivers, m.Receiver)
+		s.alerts[fp] = &entry{alert: ga, receivedAt: now}
+	}
+	return dropped
+}
+
+// GetAlerts implements alert.ClientService.
+// Stored alerts are always active. The filters and receiver of the given params are applied.
+func (s *Store) GetAlerts(params *alert.GetAlertsParams, _ ...alert.ClientOption) (*alert.GetAlertsOK, error) {
+	if params == nil {
+		params = alert.NewGetAlertsParams()
+	}
+	if params.Active != nil && !*params.Active {
+		return &alert.GetAlertsOK{}, nil
+	}
+
+	matchers := make([]*labels.Matcher, 0, len(params.Filter))
+	for _, f := range params.Filter {
+		m, err := labels.ParseMatcher(f)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: %w", f, err)
+		}
+		matchers = append(matchers, m)
+	}
+	var receiver *regexp.Regexp
+	if params.Receiver != nil {
+		var err error
+		receiver, err = regexp.Compile("^(?:" + *params.Receiver + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid receiver %q: %w", *params.Receiver, err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire(time.Now())
+
+	as := make([]*models.GettableAlert, 0, len(s.alerts))
+	for _, e := range s.alerts {
+		if matches(e.alert, matchers, receiver) {
+			as = append(as, e.alert)
+		}
+	}
+	return &alert.GetAlertsOK{Payload: as}, nil
+}
+
+// PostAlerts implements alert.ClientService.
+// Posting alerts is not supported.
+func (s *Store) PostAlerts(_ *alert.PostAlertsParams, _ ...alert.ClientOption) (*alert.PostAlertsOK, error) {
+	return nil, errors.New("posting alerts is not supported in webhook mode")
+}
+
+// SetTransport implements alert.ClientService.
+// It does nothing as the store does not use a transport.
+func (s *Store) SetTransport(_ runtime.ClientTransport) {}
+
+func (s *Store) maxAlerts() int {
+	if s.MaxAlerts == 0 {
+		return DefaultMaxAlerts
+	}
+	return s.MaxAlerts
+}
+
+// expire removes alerts whose EndsAt passed or that were not received for TTL.
+func (s *Store) expire(now time.Time) {
+	ttl := s.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	for fp, e := range s.alerts {
+		endsAt := time.Time(*e.alert.EndsAt)
+		if (!endsAt.IsZero() && now.After(endsAt)) || now.Sub(e.receivedAt) > ttl {
+			delete(s.alerts, fp)
+		}
+	}
+}
+
+func matches(a *models.GettableAlert, matchers []*labels.Matcher, receiver *regexp.Regexp) bool {
+	for _, m := range matchers {
+		if !m.Matches(a.Labels[m.Name]) {
+			return false
+		}
+	}
+	if receiver == nil {
+		return true
+	}
+	for _, r := range a.Receivers {
+		if receiver.MatchString(*r.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func newGettableAlert(fp string, a Alert, receivedAt time.Time) *models.GettableAlert {
+	state := models.AlertStatusStateActive
+	startsAt := strfmt.DateTime(a.StartsAt)
+	endsAt := strfmt.DateTime(a.EndsAt)
+	updatedAt := strfmt.DateTime(receivedAt)
+	return &models.GettableAlert{
+		Alert: models.Alert{
+			Labels:       a.Labels,
+			GeneratorURL: strfmt.URI(a.GeneratorURL),
+		},
+		Annotations: a.Annotations,
+		Fingerprint: &fp,
+		StartsAt:    &startsAt,
+		EndsAt:      &endsAt,
+		UpdatedAt:   &updatedAt,
+		Status: &models.AlertStatus{
+			State:       &state,
+			SilencedBy:  []string{},
+			InhibitedBy: []string{},
+		},
+	}
+}
+
+// mergeReceivers returns the given receivers with the given name added if not already present.
+// The given slice is not modified as it may be shared with previously returned alerts.
+func mergeReceivers(rs []*models.Receiver, name string) []*models.Receiver {
+	for _, r := range rs {
+		if *r.Name == name {
+			return rs
+		}
+	}
+	return append(slices.Clip(rs), &models.Receiver{Name: &name})
+}
+
+func toLabelSet(m map[string]string) model.LabelSet {
+	ls := make(model.LabelSet, len(m))
+	for k, v := range m {
+		ls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return ls
+}
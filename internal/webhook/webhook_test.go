@@ -0,0 +1,156 @@
+package webhook_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/appuio/alerts_exporter/internal/webhook"
+)
+
+func TestStore_ServeHTTP(t *testing.T) {
+	subject := &webhook.Store{}
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		subject.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body)))
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, post(`{
+  "version": "4",
+  "status": "firing",
+  "receiver": "team-a",
+  "alerts": [
+    {"status": "firing", "labels": {"alertname": "ImportantAlert", "severity": "critical"}, "annotations": {"summary": "Important"}, "startsAt": "2023-11-14T22:13:20Z", "endsAt": "0001-01-01T00:00:00Z", "fingerprint": "a"},
+    {"status": "firing", "labels": {"alertname": "OtherAlert", "severity": "warning"}, "startsAt": "2023-11-14T22:13:20Z", "endsAt": "0001-01-01T00:00:00Z", "fingerprint": "b"}
+  ]
+}`))
+	require.Equal(t, http.StatusOK, post(`{
+  "version": "4",
+  "status": "firing",
+  "receiver": "team-b",
+  "alerts": [
+    {"status": "firing", "labels": {"alertname": "ImportantAlert", "severity": "critical"}, "startsAt": "2023-11-14T22:13:20Z", "endsAt": "0001-01-01T00:00:00Z", "fingerprint": "a"}
+  ]
+}`))
+
+	as, err := subject.GetAlerts(alert.NewGetAlertsParams().WithFilter([]string{`severity="critical"`}))
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 1)
+	a := as.Payload[0]
+	require.Equal(t, "ImportantAlert", a.Labels["alertname"])
+	require.Equal(t, "active", *a.Status.State)
+	require.Len(t, a.Receivers, 2)
+
+	receiver := "team-b"
+	as, err = subject.GetAlerts(alert.NewGetAlertsParams().WithReceiver(&receiver))
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 1)
+
+	require.Equal(t, http.StatusOK, post(`{
+  "version": "4",
+  "status": "resolved",
+  "receiver": "team-a",
+  "alerts": [
+    {"status": "resolved", "labels": {"alertname": "ImportantAlert", "severity": "critical"}, "startsAt": "2023-11-14T22:13:20Z", "endsAt": "2023-11-14T23:13:20Z", "fingerprint": "a"}
+  ]
+}`))
+	as, err = subject.GetAlerts(nil)
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 1)
+	require.Equal(t, "OtherAlert", as.Payload[0].Labels["alertname"])
+
+	require.Equal(t, http.StatusBadRequest, post(`{"alerts": [`))
+}
+
+func TestStore_Expire(t *testing.T) {
+	subject := &webhook.Store{TTL: time.Hour}
+
+	subject.Receive(webhook.Message{
+		Receiver: "team-a",
+		Alerts: []webhook.Alert{
+			{Status: "firing", Labels: map[string]string{"alertname": "Missed"}},
+			{Status: "firing", Labels: map[string]string{"alertname": "Ended"}, EndsAt: time.Now().Add(-time.Minute)},
+		},
+	}, time.Now().Add(-2*time.Hour))
+	subject.Receive(webhook.Message{
+		Receiver: "team-a",
+		Alerts: []webhook.Alert{
+			{Status: "firing", Labels: map[string]string{"alertname": "Recent"}, EndsAt: time.Now().Add(time.Minute)},
+		},
+	}, time.Now())
+
+	as, err := subject.GetAlerts(alert.NewGetAlertsParams())
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 1)
+	require.Equal(t, "Recent", as.Payload[0].Labels["alertname"])
+}
+
+func TestStore_MaxAlerts(t *testing.T) {
+	subject := &webhook.Store{MaxAlerts: 2}
+
+	dropped := subject.Receive(webhook.Message{
+		Receiver: "team-a",
+		Alerts: []webhook.Alert{
+			{Status: "firing", Labels: map[string]string{"alertname": "A"}},
+			{Status: "firing", Labels: map[string]string{"alertname": "B"}},
+			{Status: "firing", Labels: map[string]string{"alertname": "C"}},
+		},
+	}, time.Now())
+	require.Equal(t, 1, dropped)
+
+	dropped = subject.Receive(webhook.Message{
+		Receiver: "team-b",
+		Alerts: []webhook.Alert{
+			{Status: "firing", Labels: map[string]string{"alertname": "A"}},
+			{Status: "resolved", Labels: map[string]string{"alertname": "B"}},
+			{Status: "firing", Labels: map[string]string{"alertname": "C"}},
+		},
+	}, time.Now())
+	require.Equal(t, 0, dropped, "updates of stored alerts and alerts fitting after resolved ones are not dropped")
+
+	as, err := subject.GetAlerts(nil)
+	require.NoError(t, err)
+	names := []string{}
+	for _, a := range as.Payload {
+		names = append(names, a.Labels["alertname"])
+	}
+	require.ElementsMatch(t, []string{"A", "C"}, names)
+
+	rec := httptest.NewRecorder()
+	subject.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"receiver": "team-a", "alerts": [{"status": "firing", "labels": {"alertname": "D"}}]}`)))
+	require.Equal(t, http.StatusInsufficientStorage, rec.Code)
+}
+
+func TestStore_Authenticate(t *testing.T) {
+	body := `{"receiver": "team-a", "alerts": [{"status": "firing", "labels": {"alertname": "A"}}]}`
+	post := func(s *webhook.Store, setAuth func(*http.Request)) int {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+		setAuth(req)
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	none := func(*http.Request) {}
+
+	bearer := &webhook.Store{Authenticate: webhook.BearerToken(func() string { return "secret" })}
+	require.Equal(t, http.StatusUnauthorized, post(bearer, none))
+	require.Equal(t, http.StatusUnauthorized, post(bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }))
+	require.Equal(t, http.StatusOK, post(bearer, func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }))
+
+	basic := &webhook.Store{Authenticate: webhook.BasicAuth("alertmanager", func() string { return "secret" })}
+	require.Equal(t, http.StatusUnauthorized, post(basic, none))
+	require.Equal(t, http.StatusUnauthorized, post(basic, func(r *http.Request) { r.SetBasicAuth("other", "secret") }))
+	require.Equal(t, http.StatusUnauthorized, post(basic, func(r *http.Request) { r.SetBasicAuth("alertmanager", "wrong") }))
+	require.Equal(t, http.StatusOK, post(basic, func(r *http.Request) { r.SetBasicAuth("alertmanager", "secret") }))
+
+	as, err := basic.GetAlerts(nil)
+	require.NoError(t, err)
+	require.Len(t, as.Payload, 1)
+}
@@ -8,34 +8,89 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"regexp"
+	"strings"
 	"sync"
+	"time"
 
+	alertgroupscollector "github.com/appuio/alerts_exporter/internal/alertgroups_collector"
 	alertscollector "github.com/appuio/alerts_exporter/internal/alerts_collector"
+	"github.com/appuio/alerts_exporter/internal/amclient"
+	"github.com/appuio/alerts_exporter/internal/config"
+	"github.com/appuio/alerts_exporter/internal/fileauth"
+	"github.com/appuio/alerts_exporter/internal/haalerts"
 	"github.com/appuio/alerts_exporter/internal/healthcheck"
-	"github.com/appuio/alerts_exporter/internal/saauth"
-	openapiclient "github.com/go-openapi/runtime/client"
-	alertmanagerclient "github.com/prometheus/alertmanager/api/v2/client"
+	"github.com/appuio/alerts_exporter/internal/probe"
+	"github.com/appuio/alerts_exporter/internal/promalerts"
+	"github.com/appuio/alerts_exporter/internal/relabel"
+	silencescollector "github.com/appuio/alerts_exporter/internal/silences_collector"
+	statuscollector "github.com/appuio/alerts_exporter/internal/status_collector"
+	"github.com/appuio/alerts_exporter/internal/webhook"
+	"github.com/prometheus/alertmanager/api/v2/client/alert"
+	"github.com/prometheus/alertmanager/api/v2/client/alertgroup"
+	"github.com/prometheus/alertmanager/api/v2/client/general"
+	"github.com/prometheus/alertmanager/api/v2/client/silence"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var listenAddr, healthListenAddr string
+var configFile string
+var enableProbe bool
 
 var host string
+var useWebhook bool
+var webhookTTL time.Duration
+var webhookMaxAlerts int
+var prometheusHost string
+var peers stringSliceFlag
+var haStatusPolicy string
 var withInhibited, withSilenced, withUnprocessed, withActive bool
 var filters stringSliceFlag
+var receiver string
+var withReceivers bool
+var pollInterval time.Duration
+var invalidLabelStrategy string
+var labelPrefix, labelCollisionPolicy string
+var annotations stringSliceFlag
+var withTimestamps bool
+var aggregateBy string
+var withSilences bool
+var withAlertGroups bool
+var withStatus bool
+var resolveSuppressors bool
+var maxSeries int
+var severityPriority string
 
 var tlsCert, tlsCertKey, tlsCaCert, tlsServerName string
 var tlsInsecure bool
 var useTLS bool
-var bearerToken string
+var bearerToken, bearerTokenFile string
+var basicAuthUsername, basicAuthPasswordFile string
+var headerFileFlags stringSliceFlag
+var oauth2TokenURL, oauth2ClientID, oauth2ClientSecretFile string
+var oauth2Scopes stringSliceFlag
+var basePath string
+var headerFlags stringSliceFlag
+var tenants stringSliceFlag
 var k8sBearerTokenAuth bool
 
 func main() {
 	flag.StringVar(&listenAddr, "listen-addr", ":8080", "The addr to listen on")
 	flag.StringVar(&healthListenAddr, "health-listen-addr", ":8081", "The addr to listen on for the health check endpoint.")
 
+	flag.StringVar(&configFile, "config-file", "", "Path to a configuration file. Targets in the configuration file replace the connection flags.")
+	flag.BoolVar(&enableProbe, "enable-probe", false, "Serve the alerts of any Alertmanager given in the 'target' parameter on /probe. Connection settings are taken from the modules of the configuration file, the connection flags are not used.")
+
 	flag.StringVar(&host, "host", "localhost:9093", "The host of the Alertmanager")
+	flag.Var(&peers, "peer", "The host of a member of an Alertmanager cluster. If given, all peers are queried instead of --host and their alerts are deduplicated.")
+	flag.StringVar(&haStatusPolicy, "ha-status-policy", string(haalerts.MostSuppressed), "The status exported if Alertmanager cluster members disagree about an alert. One of most-suppressed, least-suppressed, first-peer.")
+
+	flag.BoolVar(&useWebhook, "webhook", false, "Receive alerts from Alertmanager webhook notifications on /webhook instead of querying --host. Configure a webhook receiver with 'send_resolved: true' in Alertmanager. If --bearer-token, --bearer-token-file or --basic-auth-username is given, notifications must carry these credentials.")
+	flag.DurationVar(&webhookTTL, "webhook-ttl", webhook.DefaultTTL, "Time after which alerts received by webhook are removed if no notification was received for them. Must be longer than the 'repeat_interval' of the Alertmanager routes.")
+	flag.IntVar(&webhookMaxAlerts, "webhook-max-alerts", webhook.DefaultMaxAlerts, "Maximum number of alerts stored per webhook target. New alerts exceeding the limit are dropped and the notification is answered with status 507.")
+
+	flag.StringVar(&prometheusHost, "prometheus-host", "", "The host of a Prometheus or Thanos Ruler. If given, its firing and pending alerts are queried from '/api/v1/alerts' instead of --host. Pending alerts are exported with the 'pending' alert state.")
 
 	flag.BoolVar(&useTLS, "tls", false, "Use TLS when connecting to Alertmanager")
 	flag.StringVar(&tlsCert, "tls-cert", "", "Path to client certificate for TLS authentication")
@@ -44,7 +99,20 @@ func main() {
 	flag.StringVar(&tlsServerName, "tls-server-name", "", "Server name to verify the hostname on the returned certificates. It must be a substring of either the Common Name or a Subject Alternative Name in the certificate. If empty, the hostname given in the address parameter is used.")
 	flag.BoolVar(&tlsInsecure, "insecure", false, "Disable TLS host verification")
 
-	flag.StringVar(&bearerToken, "bearer-token", "", "Bearer token to use for authentication")
+	flag.StringVar(&basePath, "base-path", "", "The path prefix of the API. Defaults to '/api/v2' for Alertmanagers and '/api/v1' for --prometheus-host.\nUsage example for Grafana Mimir: '--base-path /alertmanager/api/v2'")
+	flag.Var(&headerFlags, "header", "A static header added to every request to the API.\nUsage example: '--header \"X-Custom: value\"'")
+	flag.Var(&tenants, "tenant", "A tenant of a multi-tenant Alertmanager such as Grafana Mimir or Cortex. Each tenant is queried with the 'X-Scope-OrgID' header and its series are labeled with 'tenant'.\nUsage example: '--tenant team-a --tenant team-b'")
+
+	flag.Var(&headerFileFlags, "header-file", "A header added to every request to the API whose value is read from a file. The file is reloaded periodically.\nUsage example: '--header-file \"X-Scope-OrgID: /etc/tenant/id\"'")
+
+	flag.StringVar(&bearerToken, "bearer-token", "", "Bearer token to use for authentication. The token is visible in the process list, prefer --bearer-token-file.")
+	flag.StringVar(&bearerTokenFile, "bearer-token-file", "", "Path to a file containing the bearer token to use for authentication. The file is reloaded periodically.")
+	flag.StringVar(&basicAuthUsername, "basic-auth-username", "", "Username for basic authentication. Requires --basic-auth-password-file.")
+	flag.StringVar(&basicAuthPasswordFile, "basic-auth-password-file", "", "Path to a file containing the password for basic authentication. The file is reloaded periodically.")
+	flag.StringVar(&oauth2TokenURL, "oauth2-token-url", "", "URL of the token endpoint for OAuth2 client credentials authentication. Requires --oauth2-client-id and --oauth2-client-secret-file.")
+	flag.StringVar(&oauth2ClientID, "oauth2-client-id", "", "Client ID for OAuth2 client credentials authentication.")
+	flag.StringVar(&oauth2ClientSecretFile, "oauth2-client-secret-file", "", "Path to a file containing the client secret for OAuth2 client credentials authentication. The file is reloaded periodically.")
+	flag.Var(&oauth2Scopes, "oauth2-scope", "A scope requested for OAuth2 client credentials tokens.")
 	flag.BoolVar(&k8sBearerTokenAuth, "k8s-bearer-token-auth", false, "Use Kubernetes service account bearer token for authentication")
 
 	flag.BoolVar(&withActive, "with-active", true, "Query for active alerts")
@@ -53,61 +121,290 @@ func main() {
 	flag.BoolVar(&withUnprocessed, "with-unprocessed", true, "Query for unprocessed alerts")
 	flag.Var(&filters, "filter", "A list of Alertmanager matchers to filter alerts by. Multiple matchers are ANDed.\nUsage example: '--filter slo=\"true\" --filter severity=\"critical\"'")
 
+	flag.BoolVar(&resolveSuppressors, "resolve-suppressors", false, "Export the creators and comments of silences and the names of inhibiting alerts in 'alerts_exporter_alert_{silenced,inhibited}_by_info' metrics, joinable to the alert series by the silenced by and inhibited by labels.")
+	flag.BoolVar(&withSilences, "with-silences", false, "Export the silences of the Alertmanager in 'alerts_exporter_silence_*' metrics.")
+
+	flag.BoolVar(&withAlertGroups, "with-alert-groups", false, "Export the number of alerts per state in the alert groups of each receiver in 'alerts_exporter_alert_group_alerts' and the number of groups per receiver in 'alerts_exporter_alert_groups'.")
+
+	flag.StringVar(&receiver, "receiver", "", "A regex matching the receivers of the alerts to query. Alerts of all receivers are queried if empty.")
+	flag.BoolVar(&withReceivers, "with-receivers-label", false, "Add a '<prefix>alert_receivers' label listing the receivers each alert is routed to.")
+
+	flag.BoolVar(&withStatus, "with-status", false, "Export the version, cluster status, start time and configuration hash of the Alertmanager in 'alerts_exporter_alertmanager_*' metrics. All peers of Alertmanager clusters are queried.")
+
+	flag.StringVar(&invalidLabelStrategy, "invalid-label-strategy", string(alertscollector.ReplaceInvalidChars), "How to handle alert labels whose names are not valid Prometheus label names. One of replace, drop-label, drop-alert.")
+	flag.StringVar(&labelPrefix, "label-prefix", alertscollector.DefaultLabelPrefix, "Prefix of the labels added by the exporter, such as '<prefix>alert_state'.")
+	flag.StringVar(&labelCollisionPolicy, "label-collision-policy", string(alertscollector.PrefixOriginal), "How to handle alert labels with the same name as a label added by the exporter. One of prefix-original (rename to 'exported_<name>'), keep-original, drop-alert.")
+	flag.Var(&annotations, "annotation", "An alert annotation to export in the 'alerts_exporter_alert_annotations_info' metric. Alert series get an 'alert_fingerprint' label to join on if any annotation is exported.\nUsage example: '--annotation summary --annotation runbook_url'")
+	flag.BoolVar(&withTimestamps, "with-timestamps", false, "Export the start, update and end timestamps of alerts in 'alerts_exporter_alert_{starts,updated,ends}_at_timestamp_seconds' metrics.")
+	flag.StringVar(&aggregateBy, "aggregate-by", "", "Comma separated list of labels. If set, the number of alerts grouped by these labels and the alert state is exported in 'alerts_exporter_alerts_count' instead of one series per alert.\nUsage example: '--aggregate-by alertname,severity,namespace'")
+	flag.IntVar(&maxSeries, "max-series", 0, "Maximum number of alert series exported per scrape. Alerts exceeding the limit are dropped by severity priority, then newest first, and counted in 'alerts_exporter_alerts_truncated'. If 0, the number of series is not limited.")
+	flag.StringVar(&severityPriority, "severity-priority", strings.Join(alertscollector.DefaultSeverityPriority, ","), "Comma separated list of 'severity' label values from most to least important. Used to decide which alerts are kept if --max-series is exceeded.")
+	flag.DurationVar(&pollInterval, "poll-interval", 0, "Query Alertmanager in the background at this interval and serve scrapes from the last result. If 0, Alertmanager is queried on every scrape.")
+
 	flag.Parse()
 
-	opts := openapiclient.TLSClientOptions{
-		Certificate: tlsCert,
-		Key:         tlsCertKey,
-		CA:          tlsCaCert,
-		ServerName:  tlsServerName,
+	collectorOpts := collectorOptions{prefix: labelPrefix, annotations: annotations, withTimestamps: withTimestamps, maxSeries: maxSeries, resolveSuppressors: resolveSuppressors, withReceivers: withReceivers}
+	if severityPriority != "" {
+		collectorOpts.severityPriority = strings.Split(severityPriority, ",")
+	}
+	var err error
+	collectorOpts.invalidLabelStrategy, err = alertscollector.ParseInvalidLabelStrategy(invalidLabelStrategy)
+	if err != nil {
+		log.Fatal(err)
 	}
-	if tlsInsecure {
-		opts.InsecureSkipVerify = true
-		opts.ServerName = ""
+	collectorOpts.collisionPolicy, err = alertscollector.ParseLabelCollisionPolicy(labelCollisionPolicy)
+	if err != nil {
+		log.Fatal(err)
 	}
-	var schemes []string
-	if useTLS {
-		schemes = []string{"https"}
+	if err := alertscollector.ValidateLabelPrefix(labelPrefix); err != nil {
+		log.Fatal(err)
 	}
 
-	hc, err := openapiclient.TLSClient(opts)
+	if len(peers) > 0 || useWebhook || prometheusHost != "" {
+		host = ""
+	}
+	flagClientConfig := config.ClientConfig{
+		TLS: config.TLSConfig{
+			Enabled:    useTLS,
+			Cert:       tlsCert,
+			Key:        tlsCertKey,
+			CA:         tlsCaCert,
+			ServerName: tlsServerName,
+			Insecure:   tlsInsecure,
+		},
+		BasePath:           basePath,
+		BearerToken:        bearerToken,
+		BearerTokenFile:    bearerTokenFile,
+		K8sBearerTokenAuth: k8sBearerTokenAuth,
+	}
+	if basicAuthUsername != "" || basicAuthPasswordFile != "" {
+		flagClientConfig.BasicAuth = &config.BasicAuth{Username: basicAuthUsername, PasswordFile: basicAuthPasswordFile}
+	}
+	if oauth2TokenURL != "" || oauth2ClientID != "" || oauth2ClientSecretFile != "" {
+		flagClientConfig.OAuth2 = &config.OAuth2{
+			TokenURL:         oauth2TokenURL,
+			ClientID:         oauth2ClientID,
+			ClientSecretFile: oauth2ClientSecretFile,
+			Scopes:           oauth2Scopes,
+		}
+	}
+	flagClientConfig.Headers, err = parseHeaders(headerFlags)
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	rt := openapiclient.NewWithClient(host, alertmanagerclient.DefaultBasePath, schemes, hc)
-
-	if bearerToken != "" {
-		rt.DefaultAuthentication = openapiclient.BearerToken(bearerToken)
+	flagClientConfig.HeaderFiles, err = parseHeaders(headerFileFlags)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := flagClientConfig.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.ValidateTenants(tenants); err != nil {
+		log.Fatal(err)
+	}
+	if useWebhook && len(tenants) > 0 {
+		log.Fatal("--tenant is not supported with --webhook")
+	}
+	if useWebhook && (flagClientConfig.OAuth2 != nil || k8sBearerTokenAuth) {
+		log.Fatal("Only --bearer-token, --bearer-token-file and --basic-auth-username are supported with --webhook")
+	}
+	if webhookMaxAlerts < 1 {
+		log.Fatal("--webhook-max-alerts must be positive")
+	}
+	targets := []config.Target{{
+		Host:           host,
+		Peers:          peers,
+		Webhook:        useWebhook,
+		PrometheusHost: prometheusHost,
+		Tenants:        tenants,
+		HAStatusPolicy: haStatusPolicy,
+		ClientConfig:   flagClientConfig,
+	}}
+	modules := map[string]config.ClientConfig{}
+	var allowedOverrides []string
+	var aggregateByLabels []string
+	if aggregateBy != "" {
+		aggregateByLabels = strings.Split(aggregateBy, ",")
 	}
-	if k8sBearerTokenAuth {
-		sa, err := saauth.NewServiceAccountAuthInfoWriter("", 0)
+	if err := alertscollector.ValidateAggregateBy(aggregateByLabels); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := regexp.Compile(receiver); err != nil {
+		log.Fatalf("Invalid receiver regex: %v", err)
+	}
+	queries := []config.Query{{
+		AggregateBy: aggregateByLabels,
+		Filters:     filters,
+		Receiver:    receiver,
+		Active:      &withActive,
+		Silenced:    &withSilenced,
+		Inhibited:   &withInhibited,
+		Unprocessed: &withUnprocessed,
+	}}
+	fromConfig := false
+	if configFile != "" {
+		c, err := config.LoadFile(configFile)
 		if err != nil {
 			log.Fatal(err)
 		}
-		defer sa.Stop()
-		rt.DefaultAuthentication = sa
+		if len(c.Targets) > 0 {
+			targets = c.Targets
+			fromConfig = true
+		}
+		for name, m := range c.Modules {
+			modules[name] = m
+		}
+		allowedOverrides = c.AllowedOverrides
+		collectorOpts.relabelConfigs = c.RelabelConfigs
+		if len(c.Queries) > 0 {
+			queries = c.Queries
+		}
+	}
+	if err := config.ValidateReceivers(targets, queries); err != nil {
+		log.Fatalf("Selecting alerts by receiver is not supported for Prometheus alert sources: %v", err)
+	}
+	if _, ok := modules[probe.DefaultModule]; !ok {
+		// Probe targets are chosen by the client, never send them the credentials of the connection flags.
+		modules[probe.DefaultModule] = config.ClientConfig{}
 	}
-
-	ac := alertmanagerclient.New(rt, nil)
 
 	reg := prometheus.NewRegistry()
+	// The alerts collectors are registered separately so scrapes with overrides can replace them while keeping all other metrics.
+	alertsReg := prometheus.NewRegistry()
+	hsm := http.NewServeMux()
+	msm := http.NewServeMux()
+	var collectors []*alertscollector.AlertsCollector
 
-	reg.MustRegister(&alertscollector.AlertsCollector{
-		AlertService: ac.Alert,
+	// Targets with tenants are queried once per tenant. Their health checks are on '/healthz/[<name>/]<tenant>'.
+	perTargetHealthz := fromConfig
+	for _, tt := range expandTenants(targets) {
+		t := tt.Target
+		constLabels := prometheus.Labels{}
+		if t.Name != "" {
+			constLabels["alertmanager"] = t.Name
+		}
+		if tt.tenant != "" {
+			constLabels["tenant"] = tt.tenant
+			perTargetHealthz = true
+		}
+		if len(constLabels) == 0 {
+			constLabels = nil
+		}
 
-		WithActive:      &withActive,
-		WithSilenced:    &withSilenced,
-		WithInhibited:   &withInhibited,
-		WithUnprocessed: &withUnprocessed,
-		Filters:         filters,
-	})
+		clients, stop, err := connectTarget(t, constLabels, reg)
+		if err != nil {
+			log.Fatalf("Target %q: %v", t.Name, err)
+		}
+		defer stop()
 
-	msm := http.NewServeMux()
-	msm.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+		targetCollectors := make([]*alertscollector.AlertsCollector, 0, len(queries))
+		for _, q := range queries {
+			collector := newAlertsCollector(clients.alert, q, collectorOpts, constLabels)
+			collector.SilenceService = clients.silence
+			targetCollectors = append(targetCollectors, collector)
+			if pollInterval > 0 {
+				pc := alertscollector.NewPollingAlertsCollector(collector, pollInterval)
+				defer pc.Stop()
+				alertsReg.MustRegister(pc)
+			} else {
+				alertsReg.MustRegister(collector)
+			}
+		}
+		collectors = append(collectors, targetCollectors...)
 
-	hsm := http.NewServeMux()
-	hsm.HandleFunc("/healthz", healthcheck.HealthCheck{GeneralService: ac.General}.HandleHealthz)
+		if withSilences && clients.silence != nil {
+			reg.MustRegister(&silencescollector.SilencesCollector{
+				SilenceService: clients.silence,
+				AlertService:   clients.alert,
+				ConstLabels:    constLabels,
+			})
+		}
+		if withStatus && len(clients.instances) > 0 {
+			reg.MustRegister(&statuscollector.StatusCollector{
+				Instances:   clients.instances,
+				ConstLabels: constLabels,
+			})
+		}
+		if withAlertGroups && clients.alertGroup != nil {
+			// Alert groups are selected by the filters and receiver of the first query.
+			reg.MustRegister(&alertgroupscollector.AlertGroupsCollector{
+				AlertGroupService: clients.alertGroup,
+				Filters:           targetCollectors[0].Filters,
+				Receiver:          targetCollectors[0].Receiver,
+				ConstLabels:       constLabels,
+			})
+		}
+
+		if clients.webhook != nil {
+			webhookPath := "/webhook"
+			if fromConfig {
+				webhookPath += "/" + t.Name
+			}
+			msm.Handle(webhookPath, clients.webhook)
+		}
+
+		healthzPath := "/healthz"
+		if fromConfig {
+			healthzPath += "/" + t.Name
+		}
+		if tt.tenant != "" {
+			healthzPath += "/" + tt.tenant
+		}
+		if clients.general != nil {
+			hc := healthcheck.HealthCheck{GeneralService: clients.general}
+			hsm.HandleFunc(healthzPath, hc.HandleHealthz)
+		} else {
+			// Webhook and Prometheus targets have no Alertmanager to check.
+			hsm.HandleFunc(healthzPath, handleOK)
+		}
+	}
+	if perTargetHealthz {
+		// Individual targets are checked on /healthz/<name>.
+		// A single unreachable target must not make the exporter unhealthy.
+		hsm.HandleFunc("/healthz", handleOK)
+	}
+
+	ho := promhttp.HandlerOpts{Registry: reg}
+	if perTargetHealthz {
+		// Serve the alerts of reachable targets even if other targets fail.
+		ho.ErrorHandling = promhttp.ContinueOnError
+		ho.ErrorLog = log.Default()
+	}
+
+	metricsHandler := promhttp.HandlerFor(prometheus.Gatherers{reg, alertsReg}, ho)
+	msm.HandleFunc("/metrics", func(res http.ResponseWriter, req *http.Request) {
+		o, err := alertscollector.ParseOverrides(req.URL.Query(), allowedOverrides)
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if o.IsZero() {
+			metricsHandler.ServeHTTP(res, req)
+			return
+		}
+
+		// Overridden settings are queried directly, bypassing any polling snapshot.
+		// The other collectors are not affected by overrides and are served as usual.
+		oreg := prometheus.NewRegistry()
+		for _, c := range collectors {
+			oreg.MustRegister(o.Apply(c))
+		}
+		promhttp.HandlerFor(prometheus.Gatherers{reg, oreg}, ho).ServeHTTP(res, req)
+	})
+	if enableProbe {
+		ph := &probe.Handler{
+			Modules: modules,
+			NewCollectors: func(as alert.ClientService) []prometheus.Collector {
+				cs := make([]prometheus.Collector, 0, len(queries))
+				for _, q := range queries {
+					cs = append(cs, newAlertsCollector(as, q, collectorOpts, nil))
+				}
+				return cs
+			},
+		}
+		defer ph.Stop()
+		msm.Handle("/probe", ph)
+	}
 
 	ms := &http.Server{
 		Addr:    listenAddr,
@@ -147,6 +444,202 @@ func main() {
 	waitShutdown.Wait()
 }
 
+// collectorOptions are the export settings shared by all alerts collectors.
+type collectorOptions struct {
+	invalidLabelStrategy alertscollector.InvalidLabelStrategy
+	prefix               string
+	collisionPolicy      alertscollector.LabelCollisionPolicy
+	annotations          []string
+	withTimestamps       bool
+	maxSeries            int
+	severityPriority     []string
+	relabelConfigs       []*relabel.Config
+	resolveSuppressors   bool
+	withReceivers        bool
+}
+
+// newAlertsCollector creates a collector running the given query against the given alert service.
+// Unnamed queries export the default metric.
+func newAlertsCollector(as alertscollector.AlertSource, q config.Query, co collectorOptions, constLabels prometheus.Labels) *alertscollector.AlertsCollector {
+	c := &alertscollector.AlertsCollector{
+		AlertService: as,
+
+		WithActive:      q.Active,
+		WithSilenced:    q.Silenced,
+		WithInhibited:   q.Inhibited,
+		WithUnprocessed: q.Unprocessed,
+		Filters:         q.Filters,
+		Receiver:        q.Receiver,
+		AggregateBy:     q.AggregateBy,
+
+		InvalidLabelStrategy: co.invalidLabelStrategy,
+		LabelPrefix:          co.prefix,
+		LabelCollisionPolicy: co.collisionPolicy,
+		Annotations:          co.annotations,
+		WithTimestamps:       co.withTimestamps,
+		MaxSeries:            co.maxSeries,
+		SeverityPriority:     co.severityPriority,
+		RelabelConfigs:       co.relabelConfigs,
+		ResolveSuppressors:   co.resolveSuppressors,
+		WithReceivers:        co.withReceivers,
+
+		Query:       q.Name,
+		ConstLabels: constLabels,
+	}
+	if q.Name != "" {
+		c.MetricName = q.Metric()
+	}
+	return c
+}
+
+// tenantTarget is a target querying a single tenant.
+type tenantTarget struct {
+	config.Target
+	// tenant is empty for targets without tenants.
+	tenant string
+}
+
+// expandTenants returns one target per tenant of the given targets.
+// Targets without tenants are returned unchanged.
+func expandTenants(targets []config.Target) []tenantTarget {
+	tts := make([]tenantTarget, 0, len(targets))
+	for _, t := range targets {
+		if len(t.Tenants) == 0 {
+			tts = append(tts, tenantTarget{Target: t})
+			continue
+		}
+		for _, tenant := range t.Tenants {
+			tts = append(tts, tenantTarget{Target: t.ForTenant(tenant), tenant: tenant})
+		}
+	}
+	return tts
+}
+
+// parseHeaders parses headers given as 'Name: value'.
+func parseHeaders(hs []string) (map[string]string, error) {
+	if len(hs) == 0 {
+		return nil, nil
+	}
+	parsed := make(map[string]string, len(hs))
+	for _, h := range hs {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header %q: expected 'Name: value'", h)
+		}
+		parsed[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return parsed, nil
+}
+
+// targetClients are the API clients of a target.
+type targetClients struct {
+	alert      alertscollector.AlertSource
+	general    general.ClientService
+	silence    silence.ClientService
+	alertGroup alertgroup.ClientService
+
+	// instances are the Alertmanagers of the target, all peers for HA targets.
+	instances []statuscollector.Instance
+
+	// webhook receives the alerts of webhook targets. Only the alert service is set for webhook targets.
+	webhook *webhook.Store
+}
+
+// connectTarget creates the API clients for the given target.
+// Webhook targets receive their alerts from notifications and have no other clients.
+// Prometheus targets only have an alert source querying the rule evaluator.
+// For HA targets the alerts of all peers are merged. The first peer is used for silences, alert groups and health checks.
+// The returned stop function must be called once the clients are no longer used.
+func connectTarget(t config.Target, constLabels prometheus.Labels, reg prometheus.Registerer) (targetClients, func(), error) {
+	if t.Webhook {
+		auth, stop, err := webhookAuth(t.ClientConfig)
+		if err != nil {
+			return targetClients{}, nil, err
+		}
+		store := &webhook.Store{TTL: webhookTTL, MaxAlerts: webhookMaxAlerts, Authenticate: auth}
+		return targetClients{alert: store, webhook: store}, stop, nil
+	}
+	if t.PrometheusHost != "" {
+		rt, stop, err := amclient.NewRuntime(t.PrometheusHost, promalerts.DefaultBasePath, t.ClientConfig)
+		if err != nil {
+			return targetClients{}, nil, err
+		}
+		return targetClients{alert: &promalerts.Source{Transport: rt}}, stop, nil
+	}
+	if len(t.Peers) == 0 {
+		ac, stop, err := amclient.New(t.Host, t.ClientConfig)
+		if err != nil {
+			return targetClients{}, nil, err
+		}
+		return targetClients{
+			alert:      ac.Alert,
+			general:    ac.General,
+			silence:    ac.Silence,
+			alertGroup: ac.Alertgroup,
+			instances:  []statuscollector.Instance{{Name: t.Host, GeneralService: ac.General}},
+		}, stop, nil
+	}
+
+	policy, err := haalerts.ParseStatusPolicy(t.HAStatusPolicy)
+	if err != nil {
+		return targetClients{}, nil, err
+	}
+
+	// The peers share the transport, credentials are loaded and tokens are requested once for all peers.
+	tr, err := amclient.NewTransport(t.ClientConfig)
+	if err != nil {
+		return targetClients{}, nil, err
+	}
+	stop := tr.Stop
+	ha := &haalerts.AlertService{
+		Policy:      policy,
+		ConstLabels: constLabels,
+	}
+	clients := targetClients{alert: ha}
+	for _, p := range t.Peers {
+		ac := tr.Client(p)
+		ha.Peers = append(ha.Peers, ac.Alert)
+		clients.instances = append(clients.instances, statuscollector.Instance{Name: p, GeneralService: ac.General})
+		if clients.general == nil {
+			clients.general = ac.General
+			clients.silence = ac.Silence
+			clients.alertGroup = ac.Alertgroup
+		}
+	}
+	if err := reg.Register(ha); err != nil {
+		stop()
+		return targetClients{}, nil, err
+	}
+
+	return clients, stop, nil
+}
+
+// webhookAuth returns the authenticator checking the credentials of notifications received by a webhook target.
+// The authenticator is nil if no credentials are configured. The returned stop function stops reloading credential files.
+func webhookAuth(c config.ClientConfig) (webhook.Authenticator, func(), error) {
+	switch {
+	case c.BearerToken != "":
+		return webhook.BearerToken(func() string { return c.BearerToken }), func() {}, nil
+	case c.BearerTokenFile != "":
+		f, err := fileauth.NewFile(c.BearerTokenFile, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return webhook.BearerToken(f.Load), f.Stop, nil
+	case c.BasicAuth != nil:
+		f, err := fileauth.NewFile(c.BasicAuth.PasswordFile, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		return webhook.BasicAuth(c.BasicAuth.Username, f.Load), f.Stop, nil
+	}
+	return nil, func() {}, nil
+}
+
+func handleOK(res http.ResponseWriter, _ *http.Request) {
+	fmt.Fprintln(res, "ok")
+}
+
 type stringSliceFlag []string
 
 func (f stringSliceFlag) String() string {